@@ -1,6 +1,10 @@
 package semver_test
 
 import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/Masterminds/semver"
@@ -38,6 +42,36 @@ func BenchmarkNewConstraintUnion(b *testing.B) {
 	benchNewConstraint("~2.0.0 || =3.1.0", b)
 }
 
+func benchUnionInputs() []string {
+	inputs := make([]string, 100)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("^%d.2.0, != %d.3.1", i, i)
+	}
+	return inputs
+}
+
+func BenchmarkUnionConstraints(b *testing.B) {
+	inputs := benchUnionInputs()
+	cs := make([]*semver.Constraints, len(inputs))
+	for i, in := range inputs {
+		cs[i], _ = semver.NewConstraint(in)
+	}
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		semver.UnionConstraints(cs...)
+	}
+}
+
+func BenchmarkUnionConstraintsString(b *testing.B) {
+	inputs := benchUnionInputs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		semver.NewConstraint(strings.Join(inputs, " || "))
+	}
+}
+
 /* Check benchmarks */
 
 func benchCheckVersion(c, v string, b *testing.B) {
@@ -73,6 +107,84 @@ func BenchmarkCheckVersionUnion(b *testing.B) {
 	benchCheckVersion("~2.0.0 || =3.1.0", "3.1.0", b)
 }
 
+func BenchmarkCheckVersionExactPins(b *testing.B) {
+	constraint, _ := semver.NewConstraint("=1.0.0 || =1.0.1 || =1.1.0 || =1.2.0 || =2.0.0")
+	versions := []*semver.Version{
+		semver.MustParse("1.0.0"),
+		semver.MustParse("1.1.5"),
+		semver.MustParse("2.0.0"),
+		semver.MustParse("3.0.0"),
+	}
+
+	for i := 0; i < b.N; i++ {
+		constraint.Check(versions[i%len(versions)])
+	}
+}
+
+func benchCheckVersions() []*semver.Version {
+	versions := make([]*semver.Version, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		versions = append(versions, semver.MustParse(fmt.Sprintf("%d.%d.%d", i%4, i%10, i%7)))
+	}
+	return versions
+}
+
+func BenchmarkCheckVersionLoop(b *testing.B) {
+	constraint, _ := semver.NewConstraint(">=1.2.0, <3.0.0")
+	versions := benchCheckVersions()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		res := make([]bool, len(versions))
+		for j, v := range versions {
+			res[j] = constraint.Check(v)
+		}
+	}
+}
+
+func BenchmarkCheckAll(b *testing.B) {
+	constraint, _ := semver.NewConstraint(">=1.2.0, <3.0.0")
+	versions := benchCheckVersions()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		constraint.CheckAll(versions)
+	}
+}
+
+// benchAllowList returns a constraint allowing n exact versions, with an
+// extra range when scan is set so Check can not look the versions up.
+func benchAllowList(n int, scan bool) *semver.Constraints {
+	ors := make([]string, 0, n+1)
+	for i := 0; i < n; i++ {
+		ors = append(ors, fmt.Sprintf("=%d.%d.%d", i/100, i/10%10, i%10))
+	}
+	if scan {
+		ors = append(ors, ">=100.0.0")
+	}
+
+	c, _ := semver.NewConstraint(strings.Join(ors, " || "))
+	return c
+}
+
+func benchCheckAllowList(scan bool, b *testing.B) {
+	constraint := benchAllowList(1000, scan)
+	version, _ := semver.NewVersion("9.9.9")
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		constraint.Check(version)
+	}
+}
+
+func BenchmarkCheckAllowList(b *testing.B) {
+	benchCheckAllowList(false, b)
+}
+
+func BenchmarkCheckAllowListScan(b *testing.B) {
+	benchCheckAllowList(true, b)
+}
+
 func benchValidateVersion(c, v string, b *testing.B) {
 	version, _ := semver.NewVersion(v)
 	constraint, _ := semver.NewConstraint(c)
@@ -135,6 +247,7 @@ func BenchmarkValidateVersionUnionFail(b *testing.B) {
 /* Version creation benchmarks */
 
 func benchNewVersion(v string, b *testing.B) {
+	b.ReportAllocs()
 	for i := 0; i < b.N; i++ {
 		semver.NewVersion(v)
 	}
@@ -155,3 +268,69 @@ func BenchmarkNewVersionMeta(b *testing.B) {
 func BenchmarkNewVersionMetaDash(b *testing.B) {
 	benchNewVersion("1.0.0+metadata-dash", b)
 }
+
+// benchMatchVersionRegex matches a version with SemVerRegex, which is how
+// NewVersion used to parse versions, as a baseline for its benchmarks.
+func benchMatchVersionRegex(v string, b *testing.B) {
+	re := regexp.MustCompile("^" + semver.SemVerRegex + "$")
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		re.FindStringSubmatch(v)
+	}
+}
+
+func BenchmarkMatchVersionRegexSimple(b *testing.B) {
+	benchMatchVersionRegex("1.0.0", b)
+}
+
+func BenchmarkMatchVersionRegexPre(b *testing.B) {
+	benchMatchVersionRegex("1.0.0-alpha", b)
+}
+
+func BenchmarkMatchVersionRegexMeta(b *testing.B) {
+	benchMatchVersionRegex("1.0.0+metadata", b)
+}
+
+func BenchmarkMatchVersionRegexMetaDash(b *testing.B) {
+	benchMatchVersionRegex("1.0.0+metadata-dash", b)
+}
+
+/* Interning benchmarks */
+
+// benchLoadVersions loads a catalog of versions, many of them being equal,
+// and reports the heap retained by each version of the catalog.
+func benchLoadVersions(intern bool, b *testing.B) {
+	var before, after runtime.MemStats
+	var catalog []*semver.Version
+	for i := 0; i < b.N; i++ {
+		var in semver.Interner
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		catalog = make([]*semver.Version, 10000)
+		for j := range catalog {
+			v := semver.MustParse(fmt.Sprintf("1.%d.%d", j%10, j%3))
+			if intern {
+				v = in.Intern(v)
+			}
+			catalog[j] = v
+		}
+
+		runtime.GC()
+		runtime.ReadMemStats(&after)
+	}
+
+	retained := int64(after.HeapAlloc) - int64(before.HeapAlloc)
+	b.ReportMetric(float64(retained)/float64(len(catalog)), "heap-B/version")
+	runtime.KeepAlive(catalog)
+}
+
+func BenchmarkLoadVersions(b *testing.B) {
+	benchLoadVersions(false, b)
+}
+
+func BenchmarkLoadVersionsIntern(b *testing.B) {
+	benchLoadVersions(true, b)
+}
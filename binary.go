@@ -0,0 +1,81 @@
+package semver
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ErrInvalidBinary is returned by UnmarshalBinary when the data is not a
+// version encoded by MarshalBinary.
+var ErrInvalidBinary = errors.New("Invalid binary Semantic Version")
+
+// binaryFormat is the first byte of the binary form, so the encoding can
+// change without old data being misread.
+const binaryFormat = 1
+
+// MarshalBinary implements the encoding.BinaryMarshaler interface. The version
+// is encoded as a format byte, the epoch, major, minor and patch versions as
+// unsigned varints and the pre-release and metadata as varint length prefixed
+// strings. The original string of the version is not kept.
+func (v *Version) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 1, 1+4*binary.MaxVarintLen64+len(v.pre)+len(v.metadata)+2)
+	b[0] = binaryFormat
+
+	var n [binary.MaxVarintLen64]byte
+	for _, c := range [4]int64{v.epoch, v.major, v.minor, v.patch} {
+		if c < 0 {
+			return nil, ErrInvalidSemVer
+		}
+		b = append(b, n[:binary.PutUvarint(n[:], uint64(c))]...)
+	}
+	for _, s := range [2]string{v.pre, v.metadata} {
+		b = append(b, n[:binary.PutUvarint(n[:], uint64(len(s)))]...)
+		b = append(b, s...)
+	}
+
+	return b, nil
+}
+
+// UnmarshalBinary implements the encoding.BinaryUnmarshaler interface for
+// data encoded by MarshalBinary. ErrInvalidBinary is returned when the data is
+// truncated, has trailing bytes or holds an invalid pre-release or metadata.
+// Original then gives the version as String does.
+func (v *Version) UnmarshalBinary(data []byte) error {
+	if len(data) == 0 || data[0] != binaryFormat {
+		return ErrInvalidBinary
+	}
+	data = data[1:]
+
+	var c [4]int64
+	for i := range c {
+		n, l := binary.Uvarint(data)
+		if l <= 0 || n > math.MaxInt64 {
+			return ErrInvalidBinary
+		}
+		c[i] = int64(n)
+		data = data[l:]
+	}
+
+	var s [2]string
+	for i := range s {
+		n, l := binary.Uvarint(data)
+		if l <= 0 || n > uint64(len(data)-l) {
+			return ErrInvalidBinary
+		}
+		data = data[l:]
+		s[i] = string(data[:n])
+		data = data[n:]
+		if s[i] != "" && !isValidIdentifiers(s[i]) {
+			return ErrInvalidBinary
+		}
+	}
+	if len(data) != 0 {
+		return ErrInvalidBinary
+	}
+
+	v.epoch, v.major, v.minor, v.patch = c[0], c[1], c[2], c[3]
+	v.pre, v.metadata = s[0], s[1]
+	v.original = v.String()
+	return nil
+}
@@ -0,0 +1,86 @@
+package semver
+
+import (
+	"encoding"
+	"testing"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = &Version{}
+	_ encoding.BinaryUnmarshaler = &Version{}
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	tests := []string{
+		"0.0.0",
+		"1.2.3",
+		"v1.2.3",
+		"1.2.3-beta.1",
+		"1.2.3+build.5",
+		"1.2.3-rc.1+build.5.sha-1a2b",
+		"300.4000.50000",
+		"9223372036854775807.0.1",
+	}
+
+	for _, tc := range tests {
+		v := MustParse(tc)
+		b, err := v.MarshalBinary()
+		if err != nil {
+			t.Errorf("Error marshaling %q: %s", tc, err)
+			continue
+		}
+
+		got := &Version{}
+		if err := got.UnmarshalBinary(b); err != nil {
+			t.Errorf("Error unmarshaling %q: %s", tc, err)
+			continue
+		}
+
+		if !got.Equal(v) || got.Metadata() != v.Metadata() || got.String() != v.String() {
+			t.Errorf("Expected %q to round trip, got %q", v, got)
+		}
+	}
+}
+
+func TestBinaryEpoch(t *testing.T) {
+	v, err := NewVersionEpoch("2:1.0.0-rc.1")
+	if err != nil {
+		t.Fatalf("Error parsing version: %s", err)
+	}
+
+	b, _ := v.MarshalBinary()
+	got := &Version{}
+	if err := got.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Error unmarshaling: %s", err)
+	}
+	if got.Epoch() != 2 || !got.Equal(v) {
+		t.Errorf("Expected %q to round trip, got %q", v, got)
+	}
+}
+
+func TestUnmarshalBinaryMalformed(t *testing.T) {
+	valid, _ := MustParse("1.2.3-beta+build").MarshalBinary()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"unknown format", []byte{2, 0, 1, 2, 3, 0, 0}},
+		{"truncated components", []byte{1, 0, 1}},
+		{"truncated varint", []byte{1, 0, 1, 2, 0x80}},
+		{"component overflow", []byte{1, 0, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x01, 0, 0, 0, 0}},
+		{"truncated string", valid[:len(valid)-1]},
+		{"string length past end", []byte{1, 0, 1, 2, 3, 9, 'a'}},
+		{"trailing bytes", append(append([]byte{}, valid...), 0)},
+		{"invalid pre-release", []byte{1, 0, 1, 2, 3, 2, 'a', '_', 0}},
+		{"invalid metadata", []byte{1, 0, 1, 2, 3, 0, 2, 'a', '.'}},
+	}
+
+	for _, tc := range tests {
+		v := &Version{}
+		if err := v.UnmarshalBinary(tc.data); err != ErrInvalidBinary {
+			t.Errorf("Expected ErrInvalidBinary for %s, got %v", tc.name, err)
+		}
+	}
+}
@@ -0,0 +1,924 @@
+package semver
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// versionRange is the span of versions admitted by a constraint or by a group
+// of constraints. Pre-releases are not taken into account. A nil bound means
+// the range is unbounded on that side.
+type versionRange struct {
+	min, max         *Version
+	minIncl, maxIncl bool
+}
+
+// UpperBound returns the highest version the constraints allow, whether that
+// version is itself allowed and whether there is an upper bound at all. For
+// example, `^1.2.3` returns 2.0.0, false, true while `>= 1.0.0` returns nil,
+// false, false. When there are OR groups the maximum across the groups is
+// returned. Constraints without any OR group, such as NoneConstraint or a
+// branch, allow no version and return nil, false, true: they are bounded but
+// there is no version to bound them.
+func (cs Constraints) UpperBound() (v *Version, inclusive bool, bounded bool) {
+	rs := cs.ranges()
+	if len(rs) == 0 {
+		return nil, false, true
+	}
+
+	for i, r := range rs {
+		if r.max == nil {
+			return nil, false, false
+		}
+
+		if i == 0 {
+			v, inclusive = r.max, r.maxIncl
+			continue
+		}
+		if d := r.max.Compare(v); d > 0 || (d == 0 && r.maxIncl) {
+			v, inclusive = r.max, r.maxIncl
+		}
+	}
+
+	return v, inclusive, v != nil
+}
+
+// HasUpperBound tells if the constraints have an upper bound. With OR groups
+// every group has to be bounded. For example, `>=1.0.0` and
+// `^1.0.0 || >=3.0.0` are not bounded while `^1.0.0 || ~3.1.0` is.
+func (cs Constraints) HasUpperBound() bool {
+	for _, r := range cs.ranges() {
+		if r.max == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HasLowerBound tells if the constraints have a lower bound. With OR groups
+// every group has to be bounded. Since no version is lower than 0.0.0,
+// `>=0.0.0` is not a lower bound.
+func (cs Constraints) HasLowerBound() bool {
+	for _, r := range cs.ranges() {
+		if r.min == nil || (r.min.IsZero() && r.minIncl) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsAtMinimum tells if the version is the lowest version the constraints
+// allow. For example, 1.2.3 is the minimum of `^1.2.3` while 1.2.4 is not.
+// There is no minimum when a lower bound excludes its version, as in
+// `> 1.2.3`, or when the constraints are not bounded below, see
+// HasLowerBound. With OR groups the lowest bound across the groups is used.
+func (cs Constraints) IsAtMinimum(v *Version) bool {
+	if !cs.HasLowerBound() {
+		return false
+	}
+
+	var min *Version
+	minIncl := false
+	for _, r := range cs.ranges() {
+		if r.isEmpty() {
+			continue
+		}
+		if min == nil {
+			min, minIncl = r.min, r.minIncl
+			continue
+		}
+		if d := r.min.Compare(min); d < 0 || (d == 0 && r.minIncl) {
+			min, minIncl = r.min, r.minIncl
+		}
+	}
+
+	return min != nil && minIncl && v.Equal(min) && cs.Check(v)
+}
+
+// ranges returns the range of each OR group of the constraints. The floor of
+// the constraints, when set, bounds each of them.
+func (cs Constraints) ranges() []versionRange {
+	rs := make([]versionRange, len(cs.constraints))
+	for i, o := range cs.constraints {
+		var r versionRange
+		if cs.floor != nil {
+			r = versionRange{min: cs.floor, minIncl: true}
+		}
+		for _, c := range o {
+			r = r.intersect(c.bounds())
+		}
+		rs[i] = r
+	}
+
+	return rs
+}
+
+// bounds returns the range of versions a single constraint admits. A `!=`
+// constraint excludes versions from within a range and does not bound it.
+func (c *constraint) bounds() versionRange {
+	if c.matchAll {
+		return versionRange{}
+	}
+
+	switch c.op {
+	case "=":
+		if c.dirty {
+			return c.tildeBounds()
+		}
+		return versionRange{min: c.con, minIncl: true, max: c.con, maxIncl: true}
+	case ">":
+		return versionRange{min: c.con}
+	case ">=":
+		return versionRange{min: c.con, minIncl: true}
+	case "<":
+		if c.dirty {
+			return versionRange{max: c.wildcardCeiling()}
+		}
+		return versionRange{max: c.con}
+	case "<=":
+		if c.dirty {
+			return versionRange{max: c.wildcardCeiling()}
+		}
+		return versionRange{max: c.con, maxIncl: true}
+	case "~", "~=":
+		return c.tildeBounds()
+	case "^":
+		return versionRange{min: c.con, minIncl: true, max: c.caretCeiling()}
+	}
+
+	return versionRange{}
+}
+
+// tildeBounds returns the range admitted by a tilde constraint. A wildcard
+// minor version allows the whole major version.
+func (c *constraint) tildeBounds() versionRange {
+	r := versionRange{min: c.con, minIncl: true}
+
+	// ~0.0.0 is a special case where all versions are accepted.
+	if c.con.Major() == 0 && c.con.Minor() == 0 && c.con.Patch() == 0 &&
+		!c.minorDirty && !c.patchDirty {
+		return r
+	}
+
+	if c.minorDirty {
+		r.max = newVersion(c.con.Major()+1, 0, 0)
+	} else {
+		r.max = newVersion(c.con.Major(), c.con.Minor()+1, 0)
+	}
+
+	return r
+}
+
+// wildcardCeiling returns the first version above the versions matched by
+// the wildcard of the constraint. For example, 1.x gives 2.0.0 and 1.2.x
+// gives 1.3.0.
+func (c *constraint) wildcardCeiling() *Version {
+	if c.minorDirty {
+		return newVersion(c.con.Major()+1, 0, 0)
+	}
+	return newVersion(c.con.Major(), c.con.Minor()+1, 0)
+}
+
+// intersect returns the range of versions admitted by both ranges.
+func (r versionRange) intersect(o versionRange) versionRange {
+	if o.min != nil {
+		if r.min == nil {
+			r.min, r.minIncl = o.min, o.minIncl
+		} else if d := o.min.Compare(r.min); d > 0 || (d == 0 && !o.minIncl) {
+			r.min, r.minIncl = o.min, o.minIncl
+		}
+	}
+
+	if o.max != nil {
+		if r.max == nil {
+			r.max, r.maxIncl = o.max, o.maxIncl
+		} else if d := o.max.Compare(r.max); d < 0 || (d == 0 && !o.maxIncl) {
+			r.max, r.maxIncl = o.max, o.maxIncl
+		}
+	}
+
+	return r
+}
+
+// newVersion returns a version made of the given segments.
+func newVersion(major, minor, patch int64) *Version {
+	v := &Version{major: major, minor: minor, patch: patch}
+	v.original = v.String()
+	return v
+}
+
+// IsSubsetOf tells if every version satisfying the constraints also satisfies
+// the other constraints. For example, `^1.2.0` is a subset of `>= 1.0.0`.
+// Each OR group of the constraints has to fit within a single OR group of the
+// other constraints, and the versions the other group excludes with `!=` have
+// to be excluded as well. Pre-releases are not taken into account. The check
+// is conservative: it may report false for an OR group that is only covered
+// by several OR groups of the other constraints together.
+func (cs Constraints) IsSubsetOf(other *Constraints) bool {
+	ors := other.ranges()
+	for i, r := range cs.ranges() {
+		if r.isEmpty() {
+			continue
+		}
+
+		covered := false
+		for j, o := range ors {
+			if o.contains(r) && !excludesWithin(other.constraints[j], cs.constraints[i], r) {
+				covered = true
+				break
+			}
+		}
+
+		if !covered {
+			return false
+		}
+	}
+
+	return true
+}
+
+// excludesWithin tells if a group of constraints excludes, within the range r,
+// a version the other group of constraints does not exclude as well.
+func excludesWithin(group, other []*constraint, r versionRange) bool {
+	for _, c := range group {
+		if c.op != "!=" {
+			continue
+		}
+
+		if c.dirty {
+			// A wildcard exclusion removes a whole range of versions. It is
+			// only known to be harmless when it does not overlap r.
+			if c.exclusionRange().intersects(r) {
+				return true
+			}
+			continue
+		}
+
+		if !r.containsVersion(c.con) {
+			continue
+		}
+
+		excluded := false
+		for _, o := range other {
+			if o.op == "!=" && !o.dirty && o.con.Equal(c.con) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exclusionRange returns the range of versions excluded by a `!=` constraint.
+func (c *constraint) exclusionRange() versionRange {
+	r := versionRange{min: c.con, minIncl: true, max: c.con, maxIncl: true}
+	if c.dirty {
+		r.max, r.maxIncl = c.wildcardCeiling(), false
+	}
+
+	return r
+}
+
+// isEmpty tells if the range admits no version at all.
+func (r versionRange) isEmpty() bool {
+	if r.min == nil || r.max == nil {
+		return false
+	}
+
+	d := r.min.Compare(r.max)
+	return d > 0 || (d == 0 && !(r.minIncl && r.maxIncl))
+}
+
+// intersects tells if at least one version is admitted by both ranges.
+func (r versionRange) intersects(o versionRange) bool {
+	return !r.intersect(o).isEmpty()
+}
+
+// contains tells if every version admitted by o is admitted by r.
+func (r versionRange) contains(o versionRange) bool {
+	// No version is lower than 0.0.0 so starting there is the same as not
+	// having a lower bound.
+	if r.min != nil && !(r.min.IsZero() && r.minIncl) {
+		if o.min == nil {
+			return false
+		}
+		if d := o.min.Compare(r.min); d < 0 || (d == 0 && o.minIncl && !r.minIncl) {
+			return false
+		}
+	}
+
+	if r.max != nil {
+		if o.max == nil {
+			return false
+		}
+		if d := o.max.Compare(r.max); d > 0 || (d == 0 && o.maxIncl && !r.maxIncl) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsVersion tells if the version is within the range.
+func (r versionRange) containsVersion(v *Version) bool {
+	return r.contains(versionRange{min: v, minIncl: true, max: v, maxIncl: true})
+}
+
+// IntersectsRange tells if the constraints admit at least one version between
+// low and high, both included. A nil low or high leaves that side of the
+// range unbounded. Pre-releases are not taken into account.
+func (cs Constraints) IntersectsRange(low, high *Version) bool {
+	window := versionRange{min: low, minIncl: true, max: high, maxIncl: true}
+	for i, r := range cs.ranges() {
+		if overlapAdmits(cs.constraints[i], r.intersect(window)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AppliesTo tells if the constraints are relevant to a package whose published
+// versions go from minPublished to maxPublished, both included. It is
+// IntersectsRange under a name for that use: constraints entirely above or
+// below the published versions do not apply.
+func (cs Constraints) AppliesTo(minPublished, maxPublished *Version) bool {
+	return cs.IntersectsRange(minPublished, maxPublished)
+}
+
+// Disjoint tells if no version satisfies both the constraints and the other
+// constraints, such as `< 1.0.0` and `>= 2.0.0`. Pre-releases are not taken
+// into account.
+func (cs Constraints) Disjoint(other *Constraints) bool {
+	ors := other.ranges()
+	for i, r := range cs.ranges() {
+		for j, o := range ors {
+			group := append(append([]*constraint{}, cs.constraints[i]...), other.constraints[j]...)
+			if overlapAdmits(group, r.intersect(o)) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// overlapAdmits tells if a group of constraints admits a version within r,
+// r being already within the range of the group.
+func overlapAdmits(group []*constraint, r versionRange) bool {
+	if r.isEmpty() {
+		return false
+	}
+
+	// A single version is simply checked against the group.
+	if r.min != nil && r.max != nil && r.min.Equal(r.max) {
+		for _, c := range group {
+			if !c.check(r.min) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, c := range group {
+		if c.op == "!=" && c.dirty && c.exclusionRange().contains(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// CaretConstraint returns the caret constraint conventionally written for
+// the version: 1.4.2 gives `^1.4.2`. The major version is kept unless it is
+// 0, where the first non zero segment is kept, so it allows the versions of
+// `>=1.4.2, <2.0.0`, 0.4.2 the ones of `>=0.4.2, <0.5.0` and 0.0.3 the ones of
+// `>=0.0.3, <0.0.4`. A pre-release such as 2.0.0-rc.1 gives `^2.0.0-rc.1`,
+// which opts in to the pre-releases below 3.0.0. The metadata and the epoch
+// of the version are left out.
+func (v *Version) CaretConstraint() *Constraints {
+	return conventionalConstraint("^", v)
+}
+
+// CaretCeiling returns the first version above the ones the caret constraint
+// of the version allows, the exclusive upper bound of CaretConstraint: 1.4.2
+// gives 2.0.0, 0.4.2 gives 0.5.0 and 0.0.3 gives 0.0.4. The pre-release, the
+// metadata and the epoch of the version are ignored.
+func CaretCeiling(v *Version) *Version {
+	switch {
+	case v.major > 0:
+		return newVersion(v.major+1, 0, 0)
+	case v.minor > 0:
+		return newVersion(0, v.minor+1, 0)
+	default:
+		return newVersion(0, 0, v.patch+1)
+	}
+}
+
+// TildeConstraint returns the tilde constraint conventionally written for
+// the version: 1.4.2 gives `~1.4.2`, allowing the versions of
+// `>=1.4.2, <1.5.0`. The metadata and the epoch of the version are left out.
+// As `~0.0.0` allows any version, 0.0.0 gives `>=0.0.0, <0.1.0` instead.
+func (v *Version) TildeConstraint() *Constraints {
+	if v.major == 0 && v.minor == 0 && v.patch == 0 {
+		return rangeConstraint(v, newVersion(0, 1, 0))
+	}
+
+	return conventionalConstraint("~", v)
+}
+
+// conventionalConstraint returns the constraints of the operator applied to
+// the version without its metadata and epoch, such as `^1.4.2`.
+func conventionalConstraint(op string, v *Version) *Constraints {
+	c := newComparison(op, &Version{major: v.major, minor: v.minor, patch: v.patch, pre: v.pre})
+	return &Constraints{constraints: [][]*constraint{{c}}}
+}
+
+// PinWithPrereleases returns constraints allowing the release of the version
+// and any of its pre-releases: 1.4.0 gives `>=1.4.0-0, <1.4.1-0`. The `-0`
+// pre-release is the lowest pre-release of a version, lower than any named
+// pre-release such as 1.4.0-alpha. The metadata and the epoch of the version
+// are ignored.
+func PinWithPrereleases(v *Version) *Constraints {
+	min := &Version{major: v.major, minor: v.minor, patch: v.patch, pre: "0"}
+	return rangeConstraint(min, newVersion(v.major, v.minor, v.patch+1))
+}
+
+// rangeConstraint returns the constraints `>=min, <max`. When min is a
+// pre-release, max is set to the lowest pre-release of max so both sides of
+// the range opt in to pre-releases and min satisfies the constraints.
+func rangeConstraint(min, max *Version) *Constraints {
+	low := &Version{major: min.major, minor: min.minor, patch: min.patch, pre: min.pre}
+	if low.pre != "" {
+		max.pre = "0"
+	}
+
+	return NewRangeConstraint(low, max, true, false)
+}
+
+// NewRangeConstraint returns the constraints allowing the versions between
+// low and high, each end being included or not. For example, 1.2.0 and 2.0.0
+// included on the low end only give the same constraints as `>=1.2.0, <2.0.0`.
+// A nil low or high leaves that side of the range unbounded.
+func NewRangeConstraint(low, high *Version, includeLow, includeHigh bool) *Constraints {
+	var group []*constraint
+	if low != nil {
+		op := ">"
+		if includeLow {
+			op = ">="
+		}
+		group = append(group, newComparison(op, low))
+	}
+	if high != nil {
+		op := "<"
+		if includeHigh {
+			op = "<="
+		}
+		group = append(group, newComparison(op, high))
+	}
+	if len(group) == 0 {
+		c, _ := parseConstraint("*")
+		group = append(group, c)
+	}
+
+	return &Constraints{constraints: [][]*constraint{group}}
+}
+
+// newComparison returns the constraint comparing versions to v with the
+// operator, as parsing the operator followed by v would.
+func newComparison(op string, v *Version) *constraint {
+	return &constraint{
+		function: constraintOps[op],
+		msg:      constraintMsg[op],
+		op:       op,
+		con:      v,
+		orig:     v.String(),
+		exact:    op == "=",
+	}
+}
+
+// Clamp returns the version if it satisfies the constraints, otherwise the
+// closest version the constraints allow. A version above the range of the
+// constraints gives their maximum and a version below it gives their minimum,
+// so with `^1.2.3`, 1.0.0 gives 1.2.3. An exclusive lower bound, as in
+// `> 1.2.3`, gives the next patch version, 1.2.4. An exclusive upper bound
+// gives the highest release below it, segments being 64 bit integers, so a
+// version above `^1.2.3` gives 1.9223372036854775807.9223372036854775807. A
+// version within the range but excluded with `!=` gives the closest release
+// allowed, the lower one being preferred, and a pre-release left out of the
+// range gives the closest release above it, such as 1.5.0 for 1.5.0-beta and
+// `^1.0.0`. With OR groups the highest version allowed below the version is
+// preferred over the lowest one allowed above it, except for pre-releases.
+// Nil is returned when no version fits.
+func (cs Constraints) Clamp(v *Version) *Version {
+	if cs.Check(v) {
+		return v
+	}
+
+	var below, above *Version
+	for i, r := range cs.ranges() {
+		if r.isEmpty() {
+			continue
+		}
+
+		group := cs.constraints[i]
+		if c := clampDown(group, r, v); c != nil && (below == nil || c.GreaterThan(below)) {
+			below = c
+		}
+		if c := clampUp(group, r, v); c != nil && (above == nil || c.LessThan(above)) {
+			above = c
+		}
+	}
+
+	if below != nil && (above == nil || v.Prerelease() == "") {
+		return below
+	}
+	return above
+}
+
+// clampDown returns the highest version below v allowed by the group of
+// constraints whose range is r, or nil when there is none.
+func clampDown(group []*constraint, r versionRange, v *Version) *Version {
+	c := releaseBelow(v)
+	if r.max != nil && (v.Compare(r.max) > 0 || (v.Equal(r.max) && !r.maxIncl)) {
+		if r.maxIncl && groupCheck(group, r.max) {
+			return r.max
+		}
+		c = releaseBelow(r.max)
+	}
+
+	// Only the exclusions can reject a release within the range. Each one
+	// is stepped over once, so this ends.
+	for c != nil && r.containsVersion(c) {
+		x := rejecting(group, c)
+		switch {
+		case x == nil:
+			return c
+		case x.op != "!=":
+			return nil
+		case x.dirty:
+			c = releaseBelow(x.con)
+		default:
+			c = releaseBelow(c)
+		}
+	}
+
+	return nil
+}
+
+// clampUp returns the lowest version above v allowed by the group of
+// constraints whose range is r, or nil when there is none.
+func clampUp(group []*constraint, r versionRange, v *Version) *Version {
+	c := nextRelease(v)
+	if r.min != nil && (v.Compare(r.min) < 0 || (v.Equal(r.min) && !r.minIncl)) {
+		if r.minIncl && groupCheck(group, r.min) {
+			return r.min
+		}
+		c = nextRelease(r.min)
+	}
+
+	for c != nil && r.containsVersion(c) {
+		x := rejecting(group, c)
+		switch {
+		case x == nil:
+			return c
+		case x.op != "!=":
+			return nil
+		case x.dirty:
+			c = x.wildcardCeiling()
+		default:
+			c = nextRelease(c)
+		}
+	}
+
+	return nil
+}
+
+// releaseBelow returns the highest release lower than v, or nil for 0.0.0.
+// With no patch version to lower, the lower segment is set to its maximum.
+func releaseBelow(v *Version) *Version {
+	switch {
+	case v.Patch() > 0:
+		return newVersion(v.Major(), v.Minor(), v.Patch()-1)
+	case v.Minor() > 0:
+		return newVersion(v.Major(), v.Minor()-1, math.MaxInt64)
+	case v.Major() > 0:
+		return newVersion(v.Major()-1, math.MaxInt64, math.MaxInt64)
+	default:
+		return nil
+	}
+}
+
+// rejecting returns the first constraint of the group the version does not
+// meet, or nil when it meets all of them.
+func rejecting(group []*constraint, v *Version) *constraint {
+	for _, c := range group {
+		if !c.check(v) {
+			return c
+		}
+	}
+	return nil
+}
+
+// nextRelease returns the lowest release greater than the version.
+func nextRelease(v *Version) *Version {
+	if v.Prerelease() != "" {
+		return newVersion(v.Major(), v.Minor(), v.Patch())
+	}
+	return newVersion(v.Major(), v.Minor(), v.Patch()+1)
+}
+
+// groupCheck tells if the version meets every constraint of the group.
+func groupCheck(group []*constraint, v *Version) bool {
+	for _, c := range group {
+		if !c.check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+// Distance tells how far the version is from the nearest version the
+// constraints allow, in the most significant segment that differs. A positive
+// value means the version is below the range and a negative one that it is
+// above it. With `>= 1.2.0`, 1.0.5 gives 0, 2, 0: the version is 2 minor
+// versions too low. With `^1.2.3`, 3.1.0 gives -2, 0, 0 since the highest
+// allowed versions are 1.x. A version that satisfies the constraints gives
+// zeros, as does one that is only excluded by a != or by being a pre-release.
+// With OR groups the distance to the nearest group is returned.
+func (cs Constraints) Distance(v *Version) (major, minor, patch int) {
+	if cs.Check(v) {
+		return 0, 0, 0
+	}
+
+	var best [3]int64
+	found := false
+	for _, r := range cs.ranges() {
+		if r.isEmpty() {
+			continue
+		}
+
+		var d [3]int64
+		if r.max != nil && (v.Compare(r.max) > 0 || (v.Equal(r.max) && !r.maxIncl)) {
+			d = distanceAbove(v, r.max, r.maxIncl)
+		} else if r.min != nil && (v.Compare(r.min) < 0 || (v.Equal(r.min) && !r.minIncl)) {
+			min := r.min
+			if !r.minIncl {
+				min = nextRelease(min)
+			}
+			d = segmentDistance(v.Segments(), min.Segments(), 3)
+		} else {
+			return 0, 0, 0
+		}
+
+		if !found || closer(d, best) {
+			best = d
+			found = true
+		}
+	}
+
+	return int(best[0]), int(best[1]), int(best[2])
+}
+
+// distanceAbove returns the distance from a version down to a maximum. Below
+// an exclusive maximum the segments after its last non-zero one can take any
+// value, so `< 2.0.0` is treated as 1.x and `< 1.3.0` as 1.2.x.
+func distanceAbove(v, max *Version, inclusive bool) [3]int64 {
+	b := max.Segments()
+	n := len(b)
+	if !inclusive {
+		for n > 0 && b[n-1] == 0 {
+			n--
+		}
+		if n == 0 {
+			return [3]int64{}
+		}
+		b[n-1]--
+	}
+
+	return segmentDistance(v.Segments(), b, n)
+}
+
+// segmentDistance returns the difference from v to b in the first of the n
+// leading segments where they differ, the other segments being 0.
+func segmentDistance(v, b [3]int64, n int) [3]int64 {
+	var d [3]int64
+	for i := 0; i < n; i++ {
+		if v[i] != b[i] {
+			d[i] = b[i] - v[i]
+			break
+		}
+	}
+	return d
+}
+
+// closer tells if the distance a is smaller than b, comparing the absolute
+// values of the segments in order. On a tie the distance down to a range is
+// smaller, matching Clamp's preference for the versions below.
+func closer(a, b [3]int64) bool {
+	for i := range a {
+		x, y := a[i], b[i]
+		if x < 0 {
+			x = -x
+		}
+		if y < 0 {
+			y = -y
+		}
+		if x != y {
+			return x < y
+		}
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// MostSpecific returns the constraints admitting the narrowest range of
+// versions, such as a recommended pin among alternatives. The range of each
+// constraints goes from the lowest to the highest version any of its OR groups
+// admits, and ranges are compared by the gap between their ends on the major
+// version, then on the minor and patch versions. So, `1.2.3` is narrower than
+// `~1.2.3`, itself narrower than `^1.2.3`. Constraints without an upper bound,
+// such as `>= 1.0.0` or `*`, are the widest. On a tie the constraints given
+// first are returned. Nil is returned when no constraints are given.
+func MostSpecific(cs ...*Constraints) *Constraints {
+	var best *Constraints
+	var bestWidth [3]int64
+	bestBounded := false
+	for _, c := range cs {
+		w, bounded := c.width()
+		if best == nil || (bounded && !bestBounded) ||
+			(bounded == bestBounded && bounded && lessSegments(w, bestWidth)) {
+			best, bestWidth, bestBounded = c, w, bounded
+		}
+	}
+
+	return best
+}
+
+// width returns the gap between the lowest and the highest version the
+// constraints admit, segment by segment, and whether there is an upper bound.
+func (cs Constraints) width() ([3]int64, bool) {
+	var min, max *Version
+	for _, r := range cs.ranges() {
+		if r.isEmpty() {
+			continue
+		}
+		if r.max == nil {
+			return [3]int64{}, false
+		}
+
+		low := r.min
+		if low == nil {
+			low = newVersion(0, 0, 0)
+		}
+		if min == nil || low.LessThan(min) {
+			min = low
+		}
+		if max == nil || r.max.GreaterThan(max) {
+			max = r.max
+		}
+	}
+
+	if min == nil {
+		return [3]int64{}, true
+	}
+
+	a, b := min.Segments(), max.Segments()
+	return [3]int64{b[0] - a[0], b[1] - a[1], b[2] - a[2]}, true
+}
+
+// lessSegments tells if the segments a come before the segments b.
+func lessSegments(a, b [3]int64) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// CapUpper returns the constraints admitting the versions that satisfy both
+// the constraints and an upper bound of max, included or not. Each OR group is
+// intersected with the bound, so `^1.0.0` capped at 1.5.0 not included gives
+// `^1.0.0, <1.5.0`.
+func (cs Constraints) CapUpper(max *Version, inclusive bool) *Constraints {
+	op := "<"
+	if inclusive {
+		op = "<="
+	}
+
+	return cs.capped(op, max)
+}
+
+// CapLower returns the constraints admitting the versions that satisfy both
+// the constraints and a lower bound of min, included or not. Each OR group is
+// intersected with the bound.
+func (cs Constraints) CapLower(min *Version, inclusive bool) *Constraints {
+	op := ">"
+	if inclusive {
+		op = ">="
+	}
+
+	return cs.capped(op, min)
+}
+
+// capped returns a copy of the constraints with the comparison to v added to
+// each OR group.
+func (cs Constraints) capped(op string, v *Version) *Constraints {
+	if cs.casefold {
+		v = foldPrerelease(v)
+	}
+
+	or := make([][]*constraint, len(cs.constraints))
+	for i, group := range cs.constraints {
+		g := make([]*constraint, len(group), len(group)+1)
+		copy(g, group)
+		or[i] = append(g, newComparison(op, v))
+	}
+
+	return &Constraints{
+		constraints:      or,
+		warnings:         cs.warnings,
+		exacts:           exactSet(or),
+		floorPrereleases: cs.floorPrereleases,
+		casefold:         cs.casefold,
+		floor:            cs.floor,
+		sameCore:         cs.sameCore,
+	}
+}
+
+// maxMinorLines caps the number of minor lines MinorLines returns.
+const maxMinorLines = 100
+
+// MinorLines returns the minor lines the constraints admit versions in, such
+// as 1.2, 1.3 and 1.4 for `>=1.2.0, <1.5.0`, in ascending order. Lines fully
+// excluded with `!=`, as 1.3 in `>=1.2.0, <1.5.0, !=1.3.x`, are left out. It
+// returns false when the lines can not be listed: when a group has no upper
+// bound, spans more than one major version or admits more than 100 lines.
+// `^1.2.0` admits every minor line of the 1 major version and returns false.
+func (cs Constraints) MinorLines() ([]string, bool) {
+	seen := map[[2]int64]bool{}
+	for i, r := range cs.ranges() {
+		if r.isEmpty() {
+			continue
+		}
+		if r.max == nil {
+			return nil, false
+		}
+
+		lo := r.min
+		if lo == nil {
+			lo = &Version{}
+		}
+
+		// The last line is the one of the upper bound, unless the bound
+		// excludes the first version of its line.
+		major, minor := r.max.major, r.max.minor
+		if !r.maxIncl && r.max.patch == 0 {
+			if minor == 0 {
+				return nil, false
+			}
+			minor--
+		}
+		if lo.major != major {
+			return nil, false
+		}
+		if minor-lo.minor >= maxMinorLines {
+			return nil, false
+		}
+
+		for m := lo.minor; m <= minor; m++ {
+			line := versionRange{min: newVersion(major, m, 0), minIncl: true, max: newVersion(major, m+1, 0)}
+			if overlapAdmits(cs.constraints[i], r.intersect(line)) {
+				seen[[2]int64{major, m}] = true
+			}
+		}
+	}
+	if len(seen) > maxMinorLines {
+		return nil, false
+	}
+
+	keys := make([][2]int64, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i][0] < keys[j][0] || (keys[i][0] == keys[j][0] && keys[i][1] < keys[j][1])
+	})
+
+	lines := make([]string, len(keys))
+	for i, k := range keys {
+		lines[i] = fmt.Sprintf("%d.%d", k[0], k[1])
+	}
+
+	return lines, true
+}
@@ -0,0 +1,702 @@
+package semver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUpperBound(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		inclusive  bool
+		bounded    bool
+	}{
+		{"^1.2.3", "2.0.0", false, true},
+		{"^0.2.3", "0.3.0", false, true},
+		{"^0.0.3", "0.0.4", false, true},
+		{"^0.0", "0.1.0", false, true},
+		{"~1.2.3", "1.3.0", false, true},
+		{"~1", "2.0.0", false, true},
+		{"1.2.x", "1.3.0", false, true},
+		{"1.2.3", "1.2.3", true, true},
+		{"<= 2.1", "2.1.0", true, true},
+		{"< 2.1", "2.1.0", false, true},
+		{"<= 2.x", "3.0.0", false, true},
+		{"< 1.1.x", "1.2.0", false, true},
+		{"<= 1.4.x", "1.5.0", false, true},
+		{"1.0 - 2.3.4", "2.3.4", true, true},
+		{">= 1.0.0, < 2.0.0, <= 1.5.0", "1.5.0", true, true},
+		{"< 2.0.0, <= 2.0.0", "2.0.0", false, true},
+		{"^1.2.3 || < 1.0.0", "2.0.0", false, true},
+		{"< 2.0.0 || <= 2.0.0", "2.0.0", true, true},
+		{">= 1.0.0", "", false, false},
+		{"!= 1.0.0", "", false, false},
+		{"*", "", false, false},
+		{"~0.0.0", "", false, false},
+		{"^1.2.3 || >= 3.0.0", "", false, false},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		v, inclusive, bounded := c.UpperBound()
+		if bounded != tc.bounded {
+			t.Errorf("Constraint %q expected bounded %t but got %t", tc.constraint, tc.bounded, bounded)
+			continue
+		}
+		if !bounded {
+			if v != nil {
+				t.Errorf("Constraint %q expected no upper bound but got %s", tc.constraint, v)
+			}
+			continue
+		}
+		if v.String() != tc.version || inclusive != tc.inclusive {
+			t.Errorf("Constraint %q expected upper bound %s (inclusive %t) but got %s (inclusive %t)",
+				tc.constraint, tc.version, tc.inclusive, v, inclusive)
+		}
+	}
+
+	if v, inclusive, bounded := NoneConstraint().UpperBound(); v != nil || inclusive || !bounded {
+		t.Errorf("Expected NoneConstraint to be bounded without a version but got %v, %t, %t", v, inclusive, bounded)
+	}
+}
+
+func TestHasBounds(t *testing.T) {
+	tests := []struct {
+		constraint string
+		lower      bool
+		upper      bool
+	}{
+		{">= 1.0.0", true, false},
+		{"> 1.0.0", true, false},
+		{">= 0.0.0", false, false},
+		{"< 2.0.0", false, true},
+		{"<= 2.x", false, true},
+		{"^1.2.3", true, true},
+		{"1.2.3", true, true},
+		{"1.2.x", true, true},
+		{"~0.0.0", false, false},
+		{"*", false, false},
+		{"!= 1.2.3", false, false},
+		{"^1.0.0 || ~3.1.0", true, true},
+		{"^1.0.0 || >= 3.0.0", true, false},
+		{"^1.0.0 || < 0.5.0", false, true},
+		{"> 2.0.0 || < 1.0.0", false, false},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		if a := c.HasLowerBound(); a != tc.lower {
+			t.Errorf("Constraint %q expected lower bound %t but got %t", tc.constraint, tc.lower, a)
+		}
+		if a := c.HasUpperBound(); a != tc.upper {
+			t.Errorf("Constraint %q expected upper bound %t but got %t", tc.constraint, tc.upper, a)
+		}
+	}
+}
+
+func TestIsAtMinimum(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		minimum    bool
+	}{
+		{"^1.2.3", "1.2.3", true},
+		{"^1.2.3", "v1.2.3+build", true},
+		{"^1.2.3", "1.2.4", false},
+		{">= 1.2, < 2.0", "1.2.0", true},
+		{"~1.2.x", "1.2.0", true},
+		{"> 1.2.3", "1.2.3", false},
+		{"> 1.2.3", "1.2.4", false},
+		{"< 2.0.0", "0.0.0", false},
+		{"*", "0.0.0", false},
+		{"^1.2.3, != 1.2.3", "1.2.3", false},
+		{"^2.0.0 || ^1.2.3", "1.2.3", true},
+		{"^2.0.0 || ^1.2.3", "2.0.0", false},
+		{"^2.0.0 || < 1.0.0", "2.0.0", false},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		if a := c.IsAtMinimum(MustParse(tc.version)); a != tc.minimum {
+			t.Errorf("Expected %s at the minimum of %q to be %t but got %t", tc.version, tc.constraint, tc.minimum, a)
+		}
+	}
+}
+
+func TestIsSubsetOf(t *testing.T) {
+	tests := []struct {
+		constraint string
+		other      string
+		subset     bool
+	}{
+		// Proper subsets
+		{"^1.2.0", ">= 1.0.0", true},
+		{"~1.2.3", "^1.0.0", true},
+		{"1.2.3", "^1.0.0", true},
+		{">= 1.2, < 1.5", ">= 1.0.0, < 2.0.0", true},
+		{"^1.2.0 || ^3.0.0", ">= 1.0.0", true},
+		{"^1.2.0", "^1.0.0 || ^3.0.0", true},
+		{"^1.2.0, != 1.4.0", "^1.0.0, != 1.4.0", true},
+		{"^2.0.0", "^1.0.0 || >= 2.0.0, != 1.5.0", true},
+		{">= 1.2.3, < 2.0.0", "*", true},
+
+		// Equal sets
+		{"^1.2.0", "^1.2.0", true},
+		{"^1.2.0", ">= 1.2.0, < 2.0.0", true},
+		{"1.2.x", "~1.2.0", true},
+		{"*", ">= 0.0.0", true},
+
+		// Overlapping but not subsets
+		{">= 1.0.0", "^1.2.0", false},
+		{"^1.0.0", "^1.2.0", false},
+		{"^1.2.0", ">= 1.5.0", false},
+		{">= 1.5.0, < 3.0.0", "^1.0.0", false},
+		{"^1.2.0", "^1.0.0, != 1.4.0", false},
+		{"^1.2.0", "^1.0.0, != 1.4.x", false},
+		{"^1.2.0", "> 1.2.0", false},
+		{"^1.2.0 || ^3.0.0", "^1.0.0", false},
+
+		// Disjoint sets
+		{"^1.0.0", "^2.0.0", false},
+
+		// An exclusion outside of the range does not matter
+		{"^1.2.0", "^1.0.0, != 1.1.0", true},
+		{"^1.2.0", ">= 1.0.0, != 2.x", true},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		o, err := NewConstraint(tc.other)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		if a := c.IsSubsetOf(o); a != tc.subset {
+			t.Errorf("Expected %q subset of %q to be %t but got %t", tc.constraint, tc.other, tc.subset, a)
+		}
+	}
+}
+
+func TestDisjoint(t *testing.T) {
+	tests := []struct {
+		c1, c2   string
+		disjoint bool
+	}{
+		{"< 1.0.0", ">= 2.0.0", true},
+		{"< 1.0.0", ">= 0.5.0", false},
+		{"^1.2.0", "~1.4.0", false},
+		{"^1.2.0", "^2.0.0", true},
+		{"<= 1.0.0", ">= 1.0.0", false},
+		{"< 1.0.0", ">= 1.0.0", true},
+		{"1.2.3", "!= 1.2.3", true},
+		{"1.2.3", "!= 1.2.4", false},
+		{"~1.2.0", "!= 1.2.x", true},
+		{"< 1.0.0 || >= 3.0.0", "^2.0.0", true},
+		{"< 1.0.0 || >= 3.0.0", "^2.0.0 || ^3.1.0", false},
+		{"*", "1.0.0", false},
+	}
+
+	for _, tc := range tests {
+		c1, err := NewConstraint(tc.c1)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+		c2, err := NewConstraint(tc.c2)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		if a := c1.Disjoint(c2); a != tc.disjoint {
+			t.Errorf("Expected %q and %q disjoint to be %t", tc.c1, tc.c2, tc.disjoint)
+		}
+		if a := c2.Disjoint(c1); a != tc.disjoint {
+			t.Errorf("Expected %q and %q disjoint to be %t", tc.c2, tc.c1, tc.disjoint)
+		}
+	}
+}
+
+func TestIntersectsRange(t *testing.T) {
+	tests := []struct {
+		constraint string
+		low        string
+		high       string
+		intersects bool
+	}{
+		{"^1.2.0", "1.0.0", "1.5.0", true},
+		{"^1.2.0", "1.0.0", "1.2.0", true},
+		{"^1.2.0", "1.0.0", "1.1.9", false},
+		{"^1.2.0", "2.0.0", "3.0.0", false},
+		{"^1.2.0", "1.9.0", "3.0.0", true},
+		{">= 2.0.0", "1.0.0", "1.9.9", false},
+		{">= 2.0.0", "1.0.0", "", true},
+		{"< 1.0.0", "", "0.5.0", true},
+		{"> 1.0.0", "0.1.0", "1.0.0", false},
+		{"1.2.3", "1.2.3", "1.2.3", true},
+		{"!= 1.2.3", "1.2.3", "1.2.3", false},
+		{"!= 1.2.3", "1.2.3", "1.2.4", true},
+		{"^1.0.0, != 1.2.x", "1.2.0", "1.2.9", false},
+		{"^1.0.0, != 1.2.x", "1.2.0", "1.3.0", true},
+		{"^1.0.0 || ^3.0.0", "2.0.0", "2.9.9", false},
+		{"^1.0.0 || ^3.0.0", "2.0.0", "3.0.0", true},
+		{"*", "", "", true},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		var low, high *Version
+		if tc.low != "" {
+			low = MustParse(tc.low)
+		}
+		if tc.high != "" {
+			high = MustParse(tc.high)
+		}
+
+		if a := c.IntersectsRange(low, high); a != tc.intersects {
+			t.Errorf("Expected %q to intersect [%s, %s] to be %t but got %t",
+				tc.constraint, tc.low, tc.high, tc.intersects, a)
+		}
+	}
+}
+
+func TestCaretTildeConstraint(t *testing.T) {
+	tests := []struct {
+		version string
+		caret   string
+		tilde   string
+	}{
+		{"1.4.2", "^1.4.2", "~1.4.2"},
+		{"v1.4.2+build.1", "^1.4.2", "~1.4.2"},
+		{"0.4.2", "^0.4.2", "~0.4.2"},
+		{"0.0.3", "^0.0.3", "~0.0.3"},
+		{"0.0.0", "^0.0.0", ">=0.0.0, <0.1.0"},
+		{"2.0.0-rc.1", "^2.0.0-rc.1", "~2.0.0-rc.1"},
+	}
+
+	for _, tc := range tests {
+		v := MustParse(tc.version)
+
+		if a := v.CaretConstraint().String(); a != tc.caret {
+			t.Errorf("Expected the caret constraint of %s to be %q but got %q", tc.version, tc.caret, a)
+		}
+		if a := v.TildeConstraint().String(); a != tc.tilde {
+			t.Errorf("Expected the tilde constraint of %s to be %q but got %q", tc.version, tc.tilde, a)
+		}
+		if !v.CaretConstraint().Check(v) || !v.TildeConstraint().Check(v) {
+			t.Errorf("Expected %s to satisfy its own constraints", tc.version)
+		}
+	}
+
+	ranges := []struct {
+		version string
+		caret   string
+		tilde   string
+	}{
+		{"1.4.2", ">=1.4.2, <2.0.0", ">=1.4.2, <1.5.0"},
+		{"0.4.2", ">=0.4.2, <0.5.0", ">=0.4.2, <0.5.0"},
+		{"0.0.3", ">=0.0.3, <0.0.4", ">=0.0.3, <0.1.0"},
+		{"0.0.0", ">=0.0.0, <0.0.1", ">=0.0.0, <0.1.0"},
+		{"2.0.0-rc.1", ">=2.0.0-rc.1, <3.0.0-0", ">=2.0.0-rc.1, <2.1.0-0"},
+	}
+
+	var versions []string
+	for _, s := range []string{"0.0.0", "0.0.3", "0.0.4", "0.1.0", "0.4.2", "0.5.0", "1.4.2", "1.4.9", "1.5.0", "2.0.0", "2.0.1", "2.1.0", "2.5.0", "3.0.0"} {
+		versions = append(versions, s, s+"-0", s+"-rc.1", s+"-rc.2")
+	}
+
+	// The conventional constraints allow the versions of the ranges they
+	// stand for, pre-releases included.
+	for _, tc := range ranges {
+		v := MustParse(tc.version)
+		caret, _ := NewConstraint(tc.caret)
+		tilde, _ := NewConstraint(tc.tilde)
+		for _, s := range versions {
+			o := MustParse(s)
+			if v.CaretConstraint().Check(o) != caret.Check(o) {
+				t.Errorf("Expected the caret constraint of %s to agree with %q on %s", tc.version, tc.caret, s)
+			}
+			if v.TildeConstraint().Check(o) != tilde.Check(o) {
+				t.Errorf("Expected the tilde constraint of %s to agree with %q on %s", tc.version, tc.tilde, s)
+			}
+		}
+	}
+}
+
+func TestPinWithPrereleases(t *testing.T) {
+	c := PinWithPrereleases(MustParse("v1.4.0-rc.1+build"))
+	if a := c.String(); a != ">=1.4.0-0, <1.4.1-0" {
+		t.Errorf("Expected >=1.4.0-0, <1.4.1-0 but got %q", a)
+	}
+
+	tests := []struct {
+		version string
+		check   bool
+	}{
+		{"1.4.0", true},
+		{"1.4.0-alpha", true},
+		{"1.4.0-0", true},
+		{"1.4.0-1", true},
+		{"1.4.0-rc.2", true},
+		{"1.4.1", false},
+		{"1.4.1-beta", false},
+		{"1.3.9", false},
+		{"1.3.9-beta", false},
+	}
+
+	for _, tc := range tests {
+		if a := c.Check(MustParse(tc.version)); a != tc.check {
+			t.Errorf("Expected %s to give %t but got %t", tc.version, tc.check, a)
+		}
+	}
+
+	if !MustParse("1.4.0-0").LessThan(MustParse("1.4.0-alpha")) {
+		t.Error("Expected 1.4.0-0 to be lower than a named pre-release")
+	}
+}
+
+func TestClamp(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		clamped    string
+	}{
+		{"^1.2.3", "1.9.9", "1.9.9"},
+		{"^1.2.3", "1.0.0", "1.2.3"},
+		{"^1.2.3", "2.0.0", "1.9223372036854775807.9223372036854775807"},
+		{"< 2.0.0", "3.0.0", "1.9223372036854775807.9223372036854775807"},
+		{"< 1.2.0", "1.5.0", "1.1.9223372036854775807"},
+		{"< 1.2.3, != 1.2.2", "1.5.0", "1.2.1"},
+		{"^1.0.0, != 1.2.3", "1.2.3", "1.2.2"},
+		{"^1.0.0, != 1.0.0", "1.0.0", "1.0.1"},
+		{"^1.0.0, != 1.4.x", "1.4.2", "1.3.9223372036854775807"},
+		{"~1.4.0, != 1.4.x || ^2.0.0", "1.4.2", "2.0.0"},
+		{"^1.0.0", "1.5.0-beta", "1.5.0"},
+		{"^1.0.0, != 1.5.0", "1.5.0-beta", "1.5.1"},
+		{">= 1.2.3, <= 1.5.0", "2.0.0", "1.5.0"},
+		{">= 1.2.3, <= 1.5.0", "1.2.3-beta", "1.2.3"},
+		{"> 1.2.3", "1.0.0", "1.2.4"},
+		{"> 1.2.3-beta", "1.0.0", "1.2.3"},
+		{"<= 1.5.0 || >= 3.0.0", "2.0.0", "1.5.0"},
+		{"<= 1.5.0 || >= 3.0.0", "1.5.1", "1.5.0"},
+		{"< 1.5.0 || >= 3.0.0", "2.0.0", "1.4.9223372036854775807"},
+		{"< 1.5.0 || >= 3.0.0", "2.0.0-beta", "3.0.0"},
+		{"^1.2.3, != 1.2.3", "1.0.0", "1.2.4"},
+		{"!= 1.x", "1.5.0", "0.9223372036854775807.9223372036854775807"},
+		{">= 2.0.0, < 1.0.0", "1.5.0", ""},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		a := c.Clamp(MustParse(tc.version))
+		if tc.clamped == "" {
+			if a != nil {
+				t.Errorf("Expected %s clamped to %q to be nil but got %s", tc.version, tc.constraint, a)
+			}
+			continue
+		}
+		if a == nil || a.String() != tc.clamped {
+			t.Errorf("Expected %s clamped to %q to be %s but got %v", tc.version, tc.constraint, tc.clamped, a)
+		}
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		distance   [3]int
+	}{
+		{">= 1.2.0", "1.0.5", [3]int{0, 2, 0}},
+		{">= 1.2.0", "1.4.0", [3]int{0, 0, 0}},
+		{"^1.2.3", "1.2.1", [3]int{0, 0, 2}},
+		{"^1.2.3", "0.9.0", [3]int{1, 0, 0}},
+		{"^1.2.3", "2.0.0", [3]int{-1, 0, 0}},
+		{"^1.2.3", "3.1.0", [3]int{-2, 0, 0}},
+		{"~1.2.3", "1.5.0", [3]int{0, -3, 0}},
+		{"<= 1.5.0", "1.5.4", [3]int{0, 0, -4}},
+		{"> 1.2.3", "1.2.3", [3]int{0, 0, 1}},
+		{"<= 1.5.0 || >= 3.0.0", "2.9.0", [3]int{-1, 0, 0}},
+		{">= 3.0.0 || <= 1.5.0", "2.9.0", [3]int{-1, 0, 0}},
+		{"<= 1.5.0 || >= 3.0.0", "2.9.0-beta", [3]int{-1, 0, 0}},
+		{"<= 1.5.0 || >= 2.10.0", "2.9.0", [3]int{0, 1, 0}},
+		{"<= 1.5.0 || >= 1.8.0", "1.6.0", [3]int{0, -1, 0}},
+		{"^1.2.3, != 1.4.0", "1.4.0", [3]int{0, 0, 0}},
+		{"^1.2.3", "1.4.0-beta", [3]int{0, 0, 0}},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		var a [3]int
+		a[0], a[1], a[2] = c.Distance(MustParse(tc.version))
+		if a != tc.distance {
+			t.Errorf("Expected distance from %s to %q to be %v but got %v", tc.version, tc.constraint, tc.distance, a)
+		}
+	}
+}
+
+func TestNewRangeConstraint(t *testing.T) {
+	tests := []struct {
+		low, high               string
+		includeLow, includeHigh bool
+		constraint              string
+	}{
+		{"1.2.0", "2.0.0", true, true, ">=1.2.0, <=2.0.0"},
+		{"1.2.0", "2.0.0", true, false, ">=1.2.0, <2.0.0"},
+		{"1.2.0", "2.0.0", false, true, ">1.2.0, <=2.0.0"},
+		{"1.2.0", "2.0.0", false, false, ">1.2.0, <2.0.0"},
+		{"1.2.0-beta.1", "2.0.0-0", true, false, ">=1.2.0-beta.1, <2.0.0-0"},
+		{"1.2.0", "", true, false, ">=1.2.0"},
+		{"", "2.0.0", false, true, "<=2.0.0"},
+		{"", "", false, false, "*"},
+	}
+
+	versions := []string{"0.9.0", "1.2.0", "1.2.0-beta.2", "1.2.1", "1.9.9", "2.0.0-rc.1", "2.0.0", "2.0.1"}
+	for _, tc := range tests {
+		var low, high *Version
+		if tc.low != "" {
+			low = MustParse(tc.low)
+		}
+		if tc.high != "" {
+			high = MustParse(tc.high)
+		}
+
+		c := NewRangeConstraint(low, high, tc.includeLow, tc.includeHigh)
+		if a := c.String(); a != tc.constraint {
+			t.Errorf("Expected the range to be %q but got %q", tc.constraint, a)
+		}
+
+		e, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+		for _, v := range versions {
+			if c.Check(MustParse(v)) != e.Check(MustParse(v)) {
+				t.Errorf("Expected %q to check %s like the parsed constraint", tc.constraint, v)
+			}
+		}
+	}
+}
+
+func TestMostSpecific(t *testing.T) {
+	tests := []struct {
+		constraints []string
+		expected    string
+	}{
+		{[]string{"^1.2.3", "1.2.3", "~1.2.3"}, "1.2.3"},
+		{[]string{"^1.2.3", "~1.2.3"}, "~1.2.3"},
+		{[]string{"^1.0.0", "^1.2.3"}, "^1.2.3"},
+		{[]string{">= 1.0.0", "^1.2.3"}, "^1.2.3"},
+		{[]string{"*", ">= 1.0.0"}, "*"},
+		{[]string{">= 1.0.0, < 1.5.0", "^1.0.0"}, ">=1.0.0, <1.5.0"},
+		{[]string{"~1.2.0 || ~1.4.0", "~1.2.0"}, "~1.2.0"},
+		{[]string{"< 2.0.0", "^1.0.0"}, "^1.0.0"},
+		{[]string{"<= 2.0.0", "< 2.0.0"}, "<=2.0.0"},
+	}
+
+	for _, tc := range tests {
+		cs := make([]*Constraints, len(tc.constraints))
+		for i, s := range tc.constraints {
+			c, err := NewConstraint(s)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			cs[i] = c
+		}
+
+		if a := MostSpecific(cs...).String(); a != tc.expected {
+			t.Errorf("Expected the most specific of %v to be %q but got %q", tc.constraints, tc.expected, a)
+		}
+	}
+
+	if MostSpecific() != nil {
+		t.Error("Expected nil without constraints")
+	}
+}
+
+func TestCapUpperLower(t *testing.T) {
+	tests := []struct {
+		constraint string
+		upper      bool
+		cap        string
+		inclusive  bool
+		version    string
+		check      bool
+	}{
+		{"^1.0.0", true, "1.5.0", false, "1.6.0", false},
+		{"^1.0.0", true, "1.5.0", false, "1.5.0", false},
+		{"^1.0.0", true, "1.5.0", false, "1.4.9", true},
+		{"^1.0.0", true, "1.5.0", true, "1.5.0", true},
+		{"^1.0.0 || ^3.0.0", true, "3.2.0", false, "3.3.0", false},
+		{"^1.0.0 || ^3.0.0", true, "3.2.0", false, "3.1.0", true},
+		{"^1.0.0 || ^3.0.0", true, "3.2.0", false, "1.2.0", true},
+		{">= 1.0.0", false, "1.2.0", false, "1.2.0", false},
+		{">= 1.0.0", false, "1.2.0", true, "1.2.0", true},
+		{"^1.0.0 || ^3.0.0", false, "1.5.0", true, "1.1.0", false},
+		{"^1.0.0 || ^3.0.0", false, "1.5.0", true, "3.0.0", true},
+		{"*", true, "2.0.0", false, "1.9.0", true},
+		{"*", true, "2.0.0", false, "2.0.0", false},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		var capped *Constraints
+		if tc.upper {
+			capped = c.CapUpper(MustParse(tc.cap), tc.inclusive)
+		} else {
+			capped = c.CapLower(MustParse(tc.cap), tc.inclusive)
+		}
+
+		if a := capped.Check(MustParse(tc.version)); a != tc.check {
+			t.Errorf("Expected %q capped at %s (upper %t, inclusive %t) to check %q as %t, got %t",
+				tc.constraint, tc.cap, tc.upper, tc.inclusive, tc.version, tc.check, a)
+		}
+		if !c.Check(MustParse(tc.version)) && capped.Check(MustParse(tc.version)) {
+			t.Errorf("Expected capping %q to not admit %q", tc.constraint, tc.version)
+		}
+	}
+
+	if !NoneConstraint().CapUpper(MustParse("1.0.0"), false).IsNone() {
+		t.Error("Expected a capped none constraint to still be none")
+	}
+}
+
+func TestAppliesTo(t *testing.T) {
+	tests := []struct {
+		constraint string
+		applies    bool
+	}{
+		{"^1.0.0", true},
+		{"~1.2.0", true},
+		{">= 3.0.0", false},
+		{"^4.0.0", false},
+		{"< 1.0.0", false},
+		{"^0.5.0", false},
+		{"<= 1.0.0", true},
+		{">= 2.4.0", true},
+		{"> 2.4.0", false},
+		{"^0.5.0 || ^2.0.0", true},
+	}
+
+	// The package published versions from 1.0.0 to 2.4.0.
+	min, max := MustParse("1.0.0"), MustParse("2.4.0")
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		if a := c.AppliesTo(min, max); a != tc.applies {
+			t.Errorf("Expected %q to apply to [%s, %s] to be %t but got %t", tc.constraint, min, max, tc.applies, a)
+		}
+	}
+}
+
+func TestCaretCeiling(t *testing.T) {
+	tests := []struct {
+		version string
+		ceiling string
+	}{
+		{"1.0.0", "2.0.0"},
+		{"1.4.2", "2.0.0"},
+		{"v3.0.0-rc.1+build", "4.0.0"},
+		{"0.2.0", "0.3.0"},
+		{"0.2.9", "0.3.0"},
+		{"0.0.3", "0.0.4"},
+		{"0.0.0", "0.0.1"},
+	}
+
+	for _, tc := range tests {
+		v := MustParse(tc.version)
+		if a := CaretCeiling(v).String(); a != tc.ceiling {
+			t.Errorf("Expected the caret ceiling of %s to be %s but got %s", tc.version, tc.ceiling, a)
+		}
+	}
+}
+
+func TestMinorLines(t *testing.T) {
+	tests := []struct {
+		constraint string
+		lines      []string
+		ok         bool
+	}{
+		{">= 1.2.0, < 1.5.0", []string{"1.2", "1.3", "1.4"}, true},
+		{">= 1.2.0, <= 1.5.0", []string{"1.2", "1.3", "1.4", "1.5"}, true},
+		{">= 1.2.5, < 1.5.1", []string{"1.2", "1.3", "1.4", "1.5"}, true},
+		{"~1.2.3", []string{"1.2"}, true},
+		{"1.4.x", []string{"1.4"}, true},
+		{"1.2.3", []string{"1.2"}, true},
+		{"^0.3.1", []string{"0.3"}, true},
+		{"< 0.3.0", []string{"0.0", "0.1", "0.2"}, true},
+		{">= 1.2.0, < 1.5.0, != 1.3.x", []string{"1.2", "1.4"}, true},
+		{"~1.2.0 || ~1.6.0 || 1.2.7", []string{"1.2", "1.6"}, true},
+		{"~2.1.0 || ~1.6.0", []string{"1.6", "2.1"}, true},
+		{">= 2.0.0, < 1.0.0", []string{}, true},
+		{"^1.2.0", nil, false},
+		{">= 1.2.0", nil, false},
+		{">= 1.2.0, < 2.5.0", nil, false},
+		{"*", nil, false},
+		{">= 1.0.0, < 1.200.0", nil, false},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		lines, ok := c.MinorLines()
+		if ok != tc.ok {
+			t.Errorf("Expected %q to list its minor lines %t but got %t", tc.constraint, tc.ok, ok)
+			continue
+		}
+		if ok && !reflect.DeepEqual(lines, tc.lines) {
+			t.Errorf("Expected %q to span %v but got %v", tc.constraint, tc.lines, lines)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package semver
+
+import "fmt"
+
+// BumpKind describes how a version moved forward from a previous one.
+type BumpKind int
+
+const (
+	// BumpMajor is a change of the major version.
+	BumpMajor BumpKind = iota
+
+	// BumpMinor is a change of the minor version.
+	BumpMinor
+
+	// BumpPatch is a change of the patch version.
+	BumpPatch
+
+	// BumpPrerelease is a change of the pre-release on the same major, minor
+	// and patch version (e.g., 1.2.3-beta.1 to 1.2.3-beta.2).
+	BumpPrerelease
+
+	// BumpRelease is a pre-release resolved to its release (e.g., 1.2.3-rc.1
+	// to 1.2.3).
+	BumpRelease
+
+	// BumpEpoch is a change of the epoch (e.g., 1.2.3 to 1:1.0.0), which
+	// restarts the versioning whatever the rest of the version.
+	BumpEpoch
+)
+
+// String returns the name of the bump kind.
+func (k BumpKind) String() string {
+	switch k {
+	case BumpMajor:
+		return "major"
+	case BumpMinor:
+		return "minor"
+	case BumpPatch:
+		return "patch"
+	case BumpPrerelease:
+		return "prerelease"
+	case BumpRelease:
+		return "release"
+	case BumpEpoch:
+		return "epoch"
+	default:
+		return fmt.Sprintf("BumpKind(%d)", int(k))
+	}
+}
+
+// BumpType classifies the jump from one version to the next one. The first
+// segment that differs decides of the kind, so 1.2.3 to 1.3.0 is a minor
+// bump and a change of the epoch is a BumpEpoch. When only the pre-release differs it is a pre-release bump, or a
+// release when the pre-release is dropped. An error is returned when the
+// next version is not greater than the previous one.
+func BumpType(from, to *Version) (BumpKind, error) {
+	if to.Compare(from) <= 0 {
+		return 0, fmt.Errorf("%s is not greater than %s", to, from)
+	}
+
+	switch {
+	case to.Epoch() != from.Epoch():
+		return BumpEpoch, nil
+	case to.Major() != from.Major():
+		return BumpMajor, nil
+	case to.Minor() != from.Minor():
+		return BumpMinor, nil
+	case to.Patch() != from.Patch():
+		return BumpPatch, nil
+	case to.Prerelease() == "":
+		return BumpRelease, nil
+	default:
+		return BumpPrerelease, nil
+	}
+}
+
+// BumpSequence classifies the jumps between the consecutive versions of a list
+// sorted in ascending order, such as a release history. There is one kind for
+// each pair of versions. A release following one of its pre-releases is
+// classified against the previous release of the list, so 1.2.3, 1.3.0-rc.1,
+// 1.3.0 gives a minor bump, then a minor bump again rather than a release.
+// A release without a previous release in the list gives BumpRelease. An
+// error is returned when a version is not greater than the previous one.
+func BumpSequence(vs []*Version) ([]BumpKind, error) {
+	if len(vs) < 2 {
+		return nil, nil
+	}
+
+	kinds := make([]BumpKind, 0, len(vs)-1)
+	var release *Version
+	for i := 1; i < len(vs); i++ {
+		if vs[i-1].Prerelease() == "" {
+			release = vs[i-1]
+		}
+
+		from := vs[i-1]
+		if vs[i].Prerelease() == "" && from.Prerelease() != "" && release != nil {
+			from = release
+		}
+
+		k, err := BumpType(from, vs[i])
+		if err != nil {
+			return nil, err
+		}
+		kinds = append(kinds, k)
+	}
+
+	return kinds, nil
+}
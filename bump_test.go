@@ -0,0 +1,111 @@
+package semver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBumpType(t *testing.T) {
+	tests := []struct {
+		from     string
+		to       string
+		expected BumpKind
+		err      bool
+	}{
+		{"1.2.3", "2.0.0", BumpMajor, false},
+		{"1.2.3", "1.3.0", BumpMinor, false},
+		{"1.2.3", "1.2.4", BumpPatch, false},
+		{"1.2.3", "1.3.0-beta.1", BumpMinor, false},
+		{"1.2.3-beta.1", "1.2.3-beta.2", BumpPrerelease, false},
+		{"1.2.3-rc.1", "1.2.3", BumpRelease, false},
+		{"1.2.3-rc.1", "1.2.4", BumpPatch, false},
+		{"1.2.3", "1.2.3", 0, true},
+		{"1.2.3", "1.2.3+build", 0, true},
+		{"1.3.0", "1.2.3", 0, true},
+		{"1.2.3", "1.2.3-rc.1", 0, true},
+		{"1.2.3", "1:1.2.3", BumpEpoch, false},
+		{"1.2.3", "1:0.1.0", BumpEpoch, false},
+		{"1:1.2.3", "1:1.3.0", BumpMinor, false},
+		{"1:1.2.3", "2.0.0", 0, true},
+	}
+
+	for _, tc := range tests {
+		from, err := NewVersionEpoch(tc.from)
+		if err != nil {
+			t.Errorf("Error parsing version: %s", err)
+		}
+
+		to, err := NewVersionEpoch(tc.to)
+		if err != nil {
+			t.Errorf("Error parsing version: %s", err)
+		}
+
+		a, err := BumpType(from, to)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected error for %s to %s", tc.from, tc.to)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %s to %s: %s", tc.from, tc.to, err)
+		}
+		if a != tc.expected {
+			t.Errorf("Bump from %s to %s expected %s but got %s", tc.from, tc.to, tc.expected, a)
+		}
+	}
+}
+
+func TestBumpSequence(t *testing.T) {
+	history := []string{
+		"1.0.0",
+		"1.0.1",
+		"1.0.2",
+		"1.1.0-beta.1",
+		"1.1.0-beta.2",
+		"1.1.0-rc.1",
+		"1.1.0",
+		"1.1.1",
+		"2.0.0-rc.1",
+		"2.0.0",
+		"2.0.1",
+	}
+	expected := []BumpKind{
+		BumpPatch,
+		BumpPatch,
+		BumpMinor,
+		BumpPrerelease,
+		BumpPrerelease,
+		BumpMinor,
+		BumpPatch,
+		BumpMajor,
+		BumpMajor,
+		BumpPatch,
+	}
+
+	vs := make([]*Version, len(history))
+	for i, h := range history {
+		vs[i] = MustParse(h)
+	}
+
+	kinds, err := BumpSequence(vs)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("Expected %v but got %v", expected, kinds)
+	}
+
+	kinds, err = BumpSequence([]*Version{MustParse("1.0.0-rc.1"), MustParse("1.0.0")})
+	if err != nil || !reflect.DeepEqual(kinds, []BumpKind{BumpRelease}) {
+		t.Errorf("Expected a release but got %v, %v", kinds, err)
+	}
+
+	if _, err := BumpSequence([]*Version{MustParse("1.0.0"), MustParse("1.0.0")}); err == nil {
+		t.Error("Expected an error for versions not moving forward")
+	}
+
+	if kinds, err := BumpSequence([]*Version{MustParse("1.0.0")}); err != nil || len(kinds) != 0 {
+		t.Errorf("Expected no kinds for a single version but got %v, %v", kinds, err)
+	}
+}
@@ -0,0 +1,29 @@
+package semver
+
+import "time"
+
+// AsDate reads the version as a calendar version, the major, minor and patch
+// versions being the year, month and day. For example, 2024.03.15 gives March
+// 15, 2024. A version without a day, such as 2024.3, gives the first day of the
+// month. The date is in UTC. False is returned when the segments do not form a
+// valid date, such as 2024.13.1 or 2023.2.29. The pre-release and the metadata
+// are ignored.
+func (v *Version) AsDate() (time.Time, bool) {
+	if v.major < 1 || v.major > 9999 || v.minor < 1 || v.minor > 12 || v.patch > 31 {
+		return time.Time{}, false
+	}
+
+	day := v.patch
+	if day == 0 {
+		day = 1
+	}
+
+	// time.Date normalizes days past the end of the month into the next
+	// month, which shows as a different month.
+	t := time.Date(int(v.major), time.Month(v.minor), int(day), 0, 0, 0, 0, time.UTC)
+	if t.Month() != time.Month(v.minor) {
+		return time.Time{}, false
+	}
+
+	return t, true
+}
@@ -0,0 +1,44 @@
+package semver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAsDate(t *testing.T) {
+	tests := []struct {
+		version string
+		date    string
+		ok      bool
+	}{
+		{"2024.03.15", "2024-03-15", true},
+		{"2024.3", "2024-03-01", true},
+		{"v2024.12.31-rc.1", "2024-12-31", true},
+		{"2024.2.29", "2024-02-29", true},
+		{"2023.2.29", "", false},
+		{"2024.4.31", "", false},
+		{"2024.13.1", "", false},
+		{"2024.0.1", "", false},
+		{"2024", "", false},
+		{"1.2.3", "0001-02-03", true},
+		{"0.2.3", "", false},
+		{"10000.1.1", "", false},
+	}
+
+	for _, tc := range tests {
+		d, ok := MustParse(tc.version).AsDate()
+		if ok != tc.ok {
+			t.Errorf("Expected %s to be a date to be %t", tc.version, tc.ok)
+			continue
+		}
+		if !ok {
+			if !d.IsZero() {
+				t.Errorf("Expected a zero time for %s but got %s", tc.version, d)
+			}
+			continue
+		}
+		if a := d.Format("2006-01-02"); a != tc.date || d.Location() != time.UTC {
+			t.Errorf("Expected %s to give %s but got %s", tc.version, tc.date, d)
+		}
+	}
+}
@@ -1,5 +1,11 @@
 package semver
 
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
 // Collection is a collection of Version instances and implements the sort
 // interface. See the sort package for more details.
 // https://golang.org/pkg/sort/
@@ -22,3 +28,155 @@ func (c Collection) Less(i, j int) bool {
 func (c Collection) Swap(i, j int) {
 	c[i], c[j] = c[j], c[i]
 }
+
+// DescendingCollection is a collection of Version instances that sorts from
+// the highest version to the lowest, the usual order of release lists.
+type DescendingCollection []*Version
+
+// Len returns the length of a collection. The number of Version instances
+// on the slice.
+func (c DescendingCollection) Len() int {
+	return len(c)
+}
+
+// Less is needed for the sort interface to compare two Version objects on the
+// slice. It checks if one is greater than the other.
+func (c DescendingCollection) Less(i, j int) bool {
+	return c[i].GreaterThan(c[j])
+}
+
+// Swap is needed for the sort interface to replace the Version objects
+// at two different positions in the slice.
+func (c DescendingCollection) Swap(i, j int) {
+	c[i], c[j] = c[j], c[i]
+}
+
+// SortDescending sorts a list of versions from the highest to the lowest.
+// A pre-release comes after the release it leads to.
+func SortDescending(vs []*Version) {
+	sort.Sort(DescendingCollection(vs))
+}
+
+// Max returns the greater of two versions, as told by Compare. When they are
+// equal, such as versions only differing by their metadata, a is returned.
+func Max(a, b *Version) *Version {
+	if b.GreaterThan(a) {
+		return b
+	}
+	return a
+}
+
+// Min returns the lesser of two versions, as told by Compare. When they are
+// equal, such as versions only differing by their metadata, a is returned.
+func Min(a, b *Version) *Version {
+	if b.LessThan(a) {
+		return b
+	}
+	return a
+}
+
+// MaxOf returns the greatest of the versions, the first one of them on a tie.
+// It returns nil when no version is given.
+func MaxOf(vs ...*Version) *Version {
+	var m *Version
+	for _, v := range vs {
+		if m == nil {
+			m = v
+		} else {
+			m = Max(m, v)
+		}
+	}
+	return m
+}
+
+// MinOf returns the least of the versions, the first one of them on a tie.
+// It returns nil when no version is given.
+func MinOf(vs ...*Version) *Version {
+	var m *Version
+	for _, v := range vs {
+		if m == nil {
+			m = v
+		} else {
+			m = Min(m, v)
+		}
+	}
+	return m
+}
+
+// DistinctMajors returns the major versions found in a list of versions,
+// sorted in ascending order and without duplicates.
+func DistinctMajors(vs []*Version) []int64 {
+	seen := make(map[int64]bool, len(vs))
+	var majors []int64
+	for _, v := range vs {
+		if !seen[v.Major()] {
+			seen[v.Major()] = true
+			majors = append(majors, v.Major())
+		}
+	}
+
+	sort.Slice(majors, func(i, j int) bool { return majors[i] < majors[j] })
+	return majors
+}
+
+// GroupByMajor groups a list of versions by their major version. The versions
+// of each group keep the order they have in the list.
+func GroupByMajor(vs []*Version) map[int64][]*Version {
+	groups := make(map[int64][]*Version)
+	for _, v := range vs {
+		groups[v.Major()] = append(groups[v.Major()], v)
+	}
+
+	return groups
+}
+
+// IndexedError is an error found on an entry of a list.
+type IndexedError struct {
+	// Index is the position of the entry in the list.
+	Index int
+
+	// Err is the error found on the entry.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *IndexedError) Error() string {
+	return fmt.Sprintf("entry %d: %s", e.Index, e.Err)
+}
+
+// MultiError is a list of errors reported together.
+type MultiError []error
+
+// Error implements the error interface.
+func (e MultiError) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// ParseMany parses a list of versions. The returned slice is aligned with the
+// list: the version at a given index is parsed from the string at the same
+// index, or is nil when that string can not be parsed. When some of the
+// strings can not be parsed the error is a MultiError holding an
+// *IndexedError for each of them, in order.
+func ParseMany(ss []string) ([]*Version, error) {
+	vs := make([]*Version, len(ss))
+	var errs MultiError
+	for i, s := range ss {
+		v, err := NewVersion(s)
+		if err != nil {
+			errs = append(errs, &IndexedError{Index: i, Err: err})
+			continue
+		}
+		vs[i] = v
+	}
+
+	if len(errs) > 0 {
+		return vs, errs
+	}
+
+	return vs, nil
+}
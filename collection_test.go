@@ -44,3 +44,178 @@ func TestCollection(t *testing.T) {
 		t.Error("Sorting Collection failed")
 	}
 }
+
+func TestSortDescending(t *testing.T) {
+	raw := []string{
+		"1.2.3",
+		"1.0",
+		"1.3.0-beta.2",
+		"2",
+		"1.3.0-alpha",
+		"1.3",
+		"0.4.2",
+		"1.3.0-beta.11",
+	}
+
+	vs := make([]*Version, len(raw))
+	for i, r := range raw {
+		v, err := NewVersion(r)
+		if err != nil {
+			t.Errorf("Error parsing version: %s", err)
+		}
+
+		vs[i] = v
+	}
+
+	SortDescending(vs)
+
+	e := []string{
+		"2.0.0",
+		"1.3.0",
+		"1.3.0-beta.11",
+		"1.3.0-beta.2",
+		"1.3.0-alpha",
+		"1.2.3",
+		"1.0.0",
+		"0.4.2",
+	}
+
+	a := make([]string, len(vs))
+	for i, v := range vs {
+		a[i] = v.String()
+	}
+
+	if !reflect.DeepEqual(a, e) {
+		t.Errorf("Expected %v but got %v", e, a)
+	}
+}
+
+func TestMaxMin(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		max, min string
+	}{
+		{"1.2.3", "1.2.4", "1.2.4", "1.2.3"},
+		{"2.0.0", "1.9.9", "2.0.0", "1.9.9"},
+		{"1.2.3-beta", "1.2.3", "1.2.3", "1.2.3-beta"},
+		{"1.2.3-beta.2", "1.2.3-beta.11", "1.2.3-beta.11", "1.2.3-beta.2"},
+		{"1.2.3+build.1", "1.2.3+build.2", "1.2.3+build.1", "1.2.3+build.1"},
+	}
+
+	for _, tc := range tests {
+		a, b := MustParse(tc.a), MustParse(tc.b)
+		if v := Max(a, b); v.Original() != tc.max {
+			t.Errorf("Expected max of %s and %s to be %s but got %s", tc.a, tc.b, tc.max, v.Original())
+		}
+		if v := Min(a, b); v.Original() != tc.min {
+			t.Errorf("Expected min of %s and %s to be %s but got %s", tc.a, tc.b, tc.min, v.Original())
+		}
+	}
+}
+
+func TestMaxOfMinOf(t *testing.T) {
+	vs := []*Version{
+		MustParse("1.2.3"),
+		MustParse("2.0.0-rc.1"),
+		MustParse("0.9.0"),
+		MustParse("2.0.0-beta"),
+		MustParse("0.9.0+build"),
+	}
+
+	if v := MaxOf(vs...); v.Original() != "2.0.0-rc.1" {
+		t.Errorf("Expected max to be 2.0.0-rc.1 but got %s", v.Original())
+	}
+	if v := MinOf(vs...); v.Original() != "0.9.0" {
+		t.Errorf("Expected min to be 0.9.0 but got %s", v.Original())
+	}
+	if MaxOf() != nil || MinOf() != nil {
+		t.Error("Expected nil without versions")
+	}
+}
+
+func TestDistinctMajors(t *testing.T) {
+	vs := []*Version{
+		MustParse("2.1.0"),
+		MustParse("1.0.0"),
+		MustParse("3.0.0-beta"),
+		MustParse("1.5.2"),
+		MustParse("2.0.0"),
+		MustParse("0.9.0"),
+	}
+
+	a := DistinctMajors(vs)
+	e := []int64{0, 1, 2, 3}
+	if !reflect.DeepEqual(a, e) {
+		t.Errorf("Expected majors %v but got %v", e, a)
+	}
+
+	if a := DistinctMajors(nil); len(a) != 0 {
+		t.Errorf("Expected no majors but got %v", a)
+	}
+}
+
+func TestGroupByMajor(t *testing.T) {
+	raw := []string{"2.1.0", "1.5.2", "2.0.0", "1.0.0", "3.0.0-beta"}
+	vs := make([]*Version, len(raw))
+	for i, r := range raw {
+		vs[i] = MustParse(r)
+	}
+
+	groups := GroupByMajor(vs)
+	e := map[int64][]string{
+		1: {"1.5.2", "1.0.0"},
+		2: {"2.1.0", "2.0.0"},
+		3: {"3.0.0-beta"},
+	}
+
+	a := make(map[int64][]string, len(groups))
+	for k, g := range groups {
+		for _, v := range g {
+			a[k] = append(a[k], v.String())
+		}
+	}
+
+	if !reflect.DeepEqual(a, e) {
+		t.Errorf("Expected groups %v but got %v", e, a)
+	}
+}
+
+func TestParseMany(t *testing.T) {
+	vs, err := ParseMany([]string{"1.2.3", "foo", "v2.0", "1.2.3.4"})
+	if len(vs) != 4 {
+		t.Fatalf("Expected 4 versions but got %d", len(vs))
+	}
+	if vs[0].String() != "1.2.3" || vs[1] != nil || vs[2].String() != "2.0.0" || vs[3] != nil {
+		t.Errorf("Unexpected versions %v", vs)
+	}
+
+	me, ok := err.(MultiError)
+	if !ok {
+		t.Fatalf("Expected a MultiError but got %T", err)
+	}
+	if len(me) != 2 {
+		t.Fatalf("Expected 2 errors but got %d", len(me))
+	}
+	for i, index := range []int{1, 3} {
+		ie, ok := me[i].(*IndexedError)
+		if !ok {
+			t.Fatalf("Expected an *IndexedError but got %T", me[i])
+		}
+		if ie.Index != index || ie.Err != ErrInvalidSemVer {
+			t.Errorf("Unexpected error %s", ie)
+		}
+	}
+
+	e := "entry 1: Invalid Semantic Version; entry 3: Invalid Semantic Version"
+	if err.Error() != e {
+		t.Errorf("Expected error %q but got %q", e, err)
+	}
+
+	vs, err = ParseMany([]string{"1.0.0", "2.0.0"})
+	if err != nil {
+		t.Errorf("Unexpected error: %s", err)
+	}
+	if len(vs) != 2 {
+		t.Errorf("Expected 2 versions but got %d", len(vs))
+	}
+}
@@ -4,24 +4,112 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Constraints is one or more constraint that a semantic version can be
 // checked against.
 type Constraints struct {
 	constraints [][]*constraint
+
+	// The branch name when the constraints are a branch pin.
+	branch string
+
+	// Likely mistakes found when parsing the constraints.
+	warnings []string
+
+	// The exact constraints by version when the constraints are only a list
+	// of allowed versions (e.g., 1.0.0 || 1.0.1 || 1.2.0).
+	exacts map[[4]int64][]*constraint
+
+	// Set by the AllowFloorPrereleases option.
+	floorPrereleases bool
+
+	// Set by the CasefoldPrerelease option.
+	casefold bool
+
+	// Set by the Floor option.
+	floor *Version
+
+	// Set by the SameCorePrereleases option.
+	sameCore bool
+}
+
+// ConstraintOptions changes how NewConstraintWithOptions parses constraints.
+// The zero value gives the behavior of NewConstraint.
+type ConstraintOptions struct {
+	// ErrorOnEmpty makes parsing fail when the constraints of an OR group
+	// can never be satisfied together (e.g., `>=2.0.0, <1.0.0`). Only the
+	// range of the group is looked at, versions excluded with `!=` are not.
+	ErrorOnEmpty bool
+
+	// AllowBranchTokens lists the names, such as main or develop, accepted
+	// in place of a version constraint to pin a branch. A constraint made
+	// of one of them matches no version and IsBranch reports the name.
+	AllowBranchTokens []string
+
+	// AllowFloorPrereleases admits the pre-releases of the lowest version a
+	// group of constraints allows, so `^1.2.0` matches 1.2.0-beta.3. The
+	// pre-releases of higher versions, such as 1.5.0-beta, are still left out.
+	AllowFloorPrereleases bool
+
+	// CasefoldPrerelease compares the letters of the pre-releases without
+	// regard to case when checking versions, so `1.2.0-rc.1` matches
+	// 1.2.0-RC.1. This departs from semantic versioning, see
+	// Version.CompareFold.
+	CasefoldPrerelease bool
+
+	// Floor, when set, rejects the versions below it whatever the
+	// constraints allow, so `^0.9.0` with a floor of 1.0.0 matches nothing.
+	// It is a policy applied on top of the constraints, such as never going
+	// back to versions before 1.0.0.
+	Floor *Version
+
+	// Strict makes parsing fail on the likely mistakes otherwise reported by
+	// Warnings, such as `1.2.3, 1.4.5`.
+	Strict bool
+
+	// SameCorePrereleases only admits a pre-release when a constraint of the
+	// group has a pre-release on the same major, minor and patch version, as
+	// node-semver does. So `>1.2.3-alpha.3` matches 1.2.3-alpha.7 but not
+	// 3.4.5-alpha.9, which it matches otherwise.
+	SameCorePrereleases bool
 }
 
 // NewConstraint returns a Constraints instance that a Version instance can
 // be checked against. If there is a parse error it will be returned.
 func NewConstraint(c string) (*Constraints, error) {
+	return NewConstraintWithOptions(c, ConstraintOptions{})
+}
+
+// NewConstraintWithOptions returns a Constraints instance like NewConstraint
+// does, with the parsing changed by the options.
+func NewConstraintWithOptions(c string, opts ConstraintOptions) (*Constraints, error) {
+
+	// Constraints pasted from web pages may hold non-breaking spaces and
+	// other Unicode spaces. They are handled as regular spaces.
+	c = normalizeSpace(c)
+
+	for _, b := range opts.AllowBranchTokens {
+		if strings.TrimSpace(c) == b {
+			return &Constraints{branch: b}, nil
+		}
+	}
+
+	// An empty constraint places no restriction on the version.
+	if strings.TrimSpace(c) == "" {
+		c = "*"
+	}
 
 	// Rewrite - ranges into a comparison operation.
 	c = rewriteRange(c)
 
 	ors := strings.Split(c, "||")
 	or := make([][]*constraint, len(ors))
+	var warnings []string
 	for k, v := range ors {
 		cs := strings.Split(v, ",")
 		result := make([]*constraint, len(cs))
@@ -33,17 +121,208 @@ func NewConstraint(c string) (*Constraints, error) {
 
 			result[i] = pc
 		}
+
+		if w := conflictingExacts(result); w != "" {
+			if opts.Strict {
+				return nil, fmt.Errorf("improper constraint: %s: %s", strings.TrimSpace(v), w)
+			}
+			warnings = append(warnings, w)
+		}
+
+		if opts.ErrorOnEmpty {
+			var r versionRange
+			for _, pc := range result {
+				r = r.intersect(pc.bounds())
+			}
+			if r.isEmpty() {
+				return nil, fmt.Errorf("unsatisfiable constraint: %s", strings.TrimSpace(v))
+			}
+		}
 		or[k] = result
 	}
 
-	o := &Constraints{constraints: or}
+	floor := opts.Floor
+	if opts.CasefoldPrerelease {
+		for _, o := range or {
+			for _, c := range o {
+				c.con = foldPrerelease(c.con)
+			}
+		}
+		if floor != nil {
+			floor = foldPrerelease(floor)
+		}
+	}
+
+	o := &Constraints{
+		constraints:      or,
+		warnings:         warnings,
+		exacts:           exactSet(or),
+		floorPrereleases: opts.AllowFloorPrereleases,
+		casefold:         opts.CasefoldPrerelease,
+		floor:            floor,
+		sameCore:         opts.SameCorePrereleases,
+	}
 	return o, nil
 }
 
+// exactSet indexes the constraints by version when each group of them is a
+// single exact version, so Check does not have to go through all the groups.
+// It returns nil for any other constraints.
+func exactSet(or [][]*constraint) map[[4]int64][]*constraint {
+	if len(or) < 2 {
+		return nil
+	}
+
+	set := make(map[[4]int64][]*constraint, len(or))
+	for _, o := range or {
+		if len(o) != 1 || !o[0].exact {
+			return nil
+		}
+		k := exactKey(o[0].con)
+		set[k] = append(set[k], o[0])
+	}
+
+	return set
+}
+
+func exactKey(v *Version) [4]int64 {
+	return [4]int64{v.epoch, v.major, v.minor, v.patch}
+}
+
+// conflictingExacts returns a warning when a group of constraints requires
+// two different exact versions, which no version can satisfy.
+func conflictingExacts(group []*constraint) string {
+	var first *constraint
+	for _, c := range group {
+		if !c.exact {
+			continue
+		}
+		if first == nil {
+			first = c
+			continue
+		}
+		if !c.con.Equal(first.con) {
+			return fmt.Sprintf("conflicting exact versions %s and %s", first.orig, c.orig)
+		}
+	}
+
+	return ""
+}
+
+// Warnings returns the likely mistakes found when parsing the constraints,
+// such as `1.2.3, 1.4.5` requiring two different exact versions. Parsing with
+// the Strict option turns them into errors.
+func (cs Constraints) Warnings() []string {
+	return cs.warnings
+}
+
+// UnionConstraints returns constraints satisfied by the versions satisfying
+// any of the given constraints. The OR groups of all of them are combined, in
+// order, without parsing them again. Overlapping groups are kept as they are.
+// The warnings of the constraints are kept. The options of the constraints
+// apply to all of their groups, so constraints parsed with a different Floor,
+// AllowFloorPrereleases, CasefoldPrerelease or SameCorePrereleases can not be
+// combined and give an error, as do branches and nil constraints.
+func UnionConstraints(cs ...*Constraints) (*Constraints, error) {
+	n := 0
+	for _, c := range cs {
+		switch {
+		case c == nil:
+			return nil, errors.New("nil constraints can not be combined")
+		case c.branch != "":
+			return nil, fmt.Errorf("branch constraint %s can not be combined", c.branch)
+		case !sameOptions(c, cs[0]):
+			return nil, errors.New("constraints parsed with different options can not be combined")
+		}
+		n += len(c.constraints)
+	}
+
+	or := make([][]*constraint, 0, n)
+	var warnings []string
+	for _, c := range cs {
+		or = append(or, c.constraints...)
+		warnings = append(warnings, c.warnings...)
+	}
+
+	u := &Constraints{constraints: or, warnings: warnings, exacts: exactSet(or)}
+	if len(cs) > 0 {
+		u.floorPrereleases = cs[0].floorPrereleases
+		u.casefold = cs[0].casefold
+		u.floor = cs[0].floor
+		u.sameCore = cs[0].sameCore
+	}
+
+	return u, nil
+}
+
+// sameOptions tells if two constraints were parsed with options giving the
+// same matching behavior.
+func sameOptions(a, b *Constraints) bool {
+	if a.floorPrereleases != b.floorPrereleases || a.casefold != b.casefold || a.sameCore != b.sameCore {
+		return false
+	}
+	if (a.floor == nil) != (b.floor == nil) {
+		return false
+	}
+
+	return a.floor == nil || a.floor.Equal(b.floor)
+}
+
+// Satisfies tells if a version satisfies a constraint, both given as strings.
+// An error is returned when either of them can not be parsed.
+func Satisfies(versionStr, constraintStr string) (bool, error) {
+	v, err := NewVersion(versionStr)
+	if err != nil {
+		return false, err
+	}
+
+	c, err := NewConstraint(constraintStr)
+	if err != nil {
+		return false, err
+	}
+
+	return c.Check(v), nil
+}
+
+// MustSatisfies tells if a version satisfies a constraint like Satisfies
+// does and panics when either of them can not be parsed.
+func MustSatisfies(versionStr, constraintStr string) bool {
+	ok, err := Satisfies(versionStr, constraintStr)
+	if err != nil {
+		panic(err)
+	}
+	return ok
+}
+
 // Check tests if a version satisfies the constraints.
 func (cs Constraints) Check(v *Version) bool {
+	if cs.casefold {
+		v = foldPrerelease(v)
+	}
+
+	if cs.floor != nil && v.LessThan(cs.floor) {
+		return false
+	}
+
+	if cs.floorPrereleases && cs.floorPrerelease(v) {
+		return true
+	}
+
+	if cs.exacts != nil {
+		for _, c := range cs.exacts[exactKey(v)] {
+			if c.check(v) {
+				return true
+			}
+		}
+		return false
+	}
+
 	// loop over the ORs and check the inner ANDs
 	for _, o := range cs.constraints {
+		if cs.sameCore && !sameCorePrerelease(o, v) {
+			continue
+		}
+
 		joy := true
 		for _, c := range o {
 			if !c.check(v) {
@@ -60,38 +339,477 @@ func (cs Constraints) Check(v *Version) bool {
 	return false
 }
 
+// sameCorePrerelease tells if the version is a release or if a constraint of
+// the group has a pre-release on the same version as it, as the
+// SameCorePrereleases option requires.
+func sameCorePrerelease(group []*constraint, v *Version) bool {
+	if v.pre == "" {
+		return true
+	}
+
+	for _, c := range group {
+		if c.con.pre != "" && c.con.epoch == v.epoch && c.con.major == v.major &&
+			c.con.minor == v.minor && c.con.patch == v.patch {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckInstrumented tests if a version satisfies the constraints like Check
+// does, also returning how many single constraints were evaluated to reach the
+// result. Check stops at the first failing constraint of an OR group and at
+// the first satisfied OR group, so the count tells how much of the constraints
+// a version goes through. The evaluation done for AllowFloorPrereleases is not
+// counted.
+func (cs Constraints) CheckInstrumented(v *Version) (ok bool, evaluated int) {
+	if cs.casefold {
+		v = foldPrerelease(v)
+	}
+
+	if cs.floor != nil && v.LessThan(cs.floor) {
+		return false, 0
+	}
+
+	if cs.floorPrereleases && cs.floorPrerelease(v) {
+		return true, 0
+	}
+
+	if cs.exacts != nil {
+		for _, c := range cs.exacts[exactKey(v)] {
+			evaluated++
+			if c.check(v) {
+				return true, evaluated
+			}
+		}
+		return false, evaluated
+	}
+
+	for _, o := range cs.constraints {
+		if cs.sameCore && !sameCorePrerelease(o, v) {
+			continue
+		}
+
+		joy := true
+		for _, c := range o {
+			evaluated++
+			if !c.check(v) {
+				joy = false
+				break
+			}
+		}
+
+		if joy {
+			return true, evaluated
+		}
+	}
+
+	return false, evaluated
+}
+
+// MarshalText implements the encoding.TextMarshaler interface. The text is
+// the one given by String. The options the constraints were parsed with are
+// not part of it, so they are lost once the text is parsed back. A branch
+// gives an error as UnmarshalText does not allow branch tokens.
+func (cs *Constraints) MarshalText() ([]byte, error) {
+	if cs.branch != "" {
+		return nil, fmt.Errorf("branch constraint %s can not be marshaled", cs.branch)
+	}
+
+	return []byte(cs.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface. The text
+// is parsed like NewConstraint does, without any option.
+func (cs *Constraints) UnmarshalText(text []byte) error {
+	c, err := NewConstraint(string(text))
+	if err != nil {
+		return err
+	}
+
+	*cs = *c
+	return nil
+}
+
+// floorPrerelease tells if the version is a pre-release of the lowest version
+// allowed by one of the groups of constraints. The `!=` exclusions of the
+// group still apply to the pre-release itself.
+func (cs Constraints) floorPrerelease(v *Version) bool {
+	if v.Prerelease() == "" {
+		return false
+	}
+
+	core := v.Finalize()
+	for i, r := range cs.ranges() {
+		if r.min != nil && r.minIncl && r.min.Prerelease() == "" && r.min.Equal(core) &&
+			groupCheck(cs.constraints[i], core) && exclusionsCheck(cs.constraints[i], v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// exclusionsCheck tells if the version passes all the `!=` constraints of the
+// group.
+func exclusionsCheck(group []*constraint, v *Version) bool {
+	for _, c := range group {
+		if c.op == "!=" && !c.check(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String returns the constraints in a compact form that parses back to the
+// same constraints. The operators are written with their aliases resolved and
+// the wildcards are kept as written, so `=> 1.2.x || ~>2` gives
+// `>=1.2.x || ~2`. Ranges written with a hyphen are given as the two
+// comparisons they stand for.
+func (cs Constraints) String() string {
+	if cs.branch != "" {
+		return cs.branch
+	}
+
+	// Nothing is lower than 0.0.0-0 so this keeps matching no version once
+	// parsed back.
+	if len(cs.constraints) == 0 {
+		return "<0.0.0-0"
+	}
+
+	ors := make([]string, len(cs.constraints))
+	for i, o := range cs.constraints {
+		ands := make([]string, len(o))
+		for j, c := range o {
+			ands[j] = c.String()
+		}
+		ors[i] = strings.Join(ands, ", ")
+	}
+
+	return strings.Join(ors, " || ")
+}
+
+// canonicalOrder is the order of the operators within the AND groups of
+// canonical constraints.
+var canonicalOrder = map[string]int{
+	"=": 0, "^": 1, "~": 2, "~=": 3, ">=": 4, ">": 5, "<": 6, "<=": 7, "!=": 8,
+}
+
+// Canonicalize returns the constraints with their OR groups sorted by their
+// lower bound and the constraints of each group sorted by operator, in the
+// order =, ^, ~, ~=, >=, >, <, <= and !=, then by version. Constraints only
+// differing by the order they are written in, such as `<2.0.0, >=1.0.0 ||
+// ^0.5.0` and `^0.5.0 || >=1.0.0, <2.0.0`, get the same String. This is a
+// syntactic normalization: equivalent constraints written differently, such as
+// `^1.0.0` and `>=1.0.0, <2.0.0`, stay different.
+func (cs Constraints) Canonicalize() *Constraints {
+	ranges := cs.ranges()
+	or := make([][]*constraint, len(cs.constraints))
+	order := make([]int, len(cs.constraints))
+	strs := make([]string, len(cs.constraints))
+	for i, o := range cs.constraints {
+		g := make([]*constraint, len(o))
+		copy(g, o)
+		sort.SliceStable(g, func(a, b int) bool {
+			return lessCanonical(g[a], g[b])
+		})
+		or[i] = g
+		order[i] = i
+
+		ands := make([]string, len(g))
+		for j, c := range g {
+			ands[j] = c.String()
+		}
+		strs[i] = strings.Join(ands, ", ")
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		ra, rb := ranges[order[a]], ranges[order[b]]
+		if c := compareLowerBounds(ra, rb); c != 0 {
+			return c < 0
+		}
+		return strs[order[a]] < strs[order[b]]
+	})
+
+	sorted := make([][]*constraint, len(or))
+	for i, k := range order {
+		sorted[i] = or[k]
+	}
+
+	c := cs
+	c.constraints = sorted
+	c.exacts = exactSet(sorted)
+	return &c
+}
+
+// lessCanonical tells if the constraint a comes before b in canonical
+// constraints.
+func lessCanonical(a, b *constraint) bool {
+	if a.op != b.op {
+		return canonicalOrder[a.op] < canonicalOrder[b.op]
+	}
+	if c := a.con.Compare(b.con); c != 0 {
+		return c < 0
+	}
+	return a.orig < b.orig
+}
+
+// compareLowerBounds compares the lower bounds of two ranges. No lower bound
+// comes first and an included bound comes before an excluded one.
+func compareLowerBounds(a, b versionRange) int {
+	switch {
+	case a.min == nil && b.min == nil:
+		return 0
+	case a.min == nil:
+		return -1
+	case b.min == nil:
+		return 1
+	}
+	if c := a.min.Compare(b.min); c != 0 {
+		return c
+	}
+	switch {
+	case a.minIncl == b.minIncl:
+		return 0
+	case a.minIncl:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// IsBranch returns the branch name and true when the constraints pin a branch
+// rather than versions. See ConstraintOptions.AllowBranchTokens.
+func (cs Constraints) IsBranch() (string, bool) {
+	return cs.branch, cs.branch != ""
+}
+
+// CheckAll tests each of the versions against the constraints. The result at
+// a given index tells if the version at the same index satisfies them.
+func (cs Constraints) CheckAll(vs []*Version) []bool {
+	res := make([]bool, len(vs))
+	for i, v := range vs {
+		res[i] = cs.Check(v)
+	}
+
+	return res
+}
+
+// NextLowerThan returns the highest of the candidates satisfying the
+// constraints that is lower than v, such as the best older version to fall
+// back to when v is not available. The candidates do not need to be sorted.
+// Nil is returned when none of them qualifies.
+func (cs Constraints) NextLowerThan(v *Version, candidates []*Version) *Version {
+	var best *Version
+	for _, c := range candidates {
+		if !c.LessThan(v) || (best != nil && !best.LessThan(c)) {
+			continue
+		}
+		if cs.Check(c) {
+			best = c
+		}
+	}
+
+	return best
+}
+
+// MinimumSatisfying returns the lowest of the available versions satisfying
+// every one of the constraints, as minimum version selection picks a version
+// for the requirements on a module. The available versions do not need to be
+// sorted. It returns false when no version satisfies them all, for example
+// with the conflicting `^1.0.0` and `>= 2.0.0`. Without constraints the
+// lowest available version is returned.
+func MinimumSatisfying(available []*Version, constraints ...*Constraints) (*Version, bool) {
+	var min *Version
+	for _, v := range available {
+		if min != nil && !v.LessThan(min) {
+			continue
+		}
+
+		ok := true
+		for _, c := range constraints {
+			if !c.Check(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			min = v
+		}
+	}
+
+	return min, min != nil
+}
+
 // Validate checks if a version satisfies a constraint. If not a slice of
 // reasons for the failure are returned in addition to a bool.
 func (cs Constraints) Validate(v *Version) (bool, []error) {
-	// loop over the ORs and check the inner ANDs
+	ok, groups := cs.ValidateGroups(v)
+	if ok {
+		return true, []error{}
+	}
+
 	var e []error
+	for _, g := range groups {
+		e = append(e, g...)
+	}
+
+	return false, e
+}
+
+// ValidateGroups checks if a version satisfies a constraint like Validate
+// does. When it does not, the reasons for the failure are grouped by OR
+// group, in the order of the constraint. Satisfying every constraint of
+// any one of the groups is enough for the version to pass.
+func (cs Constraints) ValidateGroups(v *Version) (bool, [][]error) {
+	if cs.casefold {
+		v = foldPrerelease(v)
+	}
+
+	below := cs.floor != nil && v.LessThan(cs.floor)
+	if !below && cs.floorPrereleases && cs.floorPrerelease(v) {
+		return true, [][]error{}
+	}
+
+	// loop over the ORs and check the inner ANDs
+	var e [][]error
 	for _, o := range cs.constraints {
-		joy := true
+		var ge []error
+		if below {
+			ge = append(ge, fmt.Errorf("%s is below the floor %s", v, cs.floor))
+		}
+		if cs.sameCore && !sameCorePrerelease(o, v) {
+			ge = append(ge, fmt.Errorf("%s is a prerelease of a version no constraint of the group has a prerelease for", v))
+		}
 		for _, c := range o {
 			if !c.check(v) {
 				em := fmt.Errorf(c.msg, v, c.orig)
-				e = append(e, em)
+				ge = append(ge, em)
+			}
+		}
+
+		if len(ge) == 0 {
+			return true, [][]error{}
+		}
+		e = append(e, ge)
+	}
+
+	return false, e
+}
+
+// NoneConstraint returns constraints that no version satisfies, 0.0.0
+// included. They are a sentinel for a conflict where nothing works.
+func NoneConstraint() *Constraints {
+	return &Constraints{}
+}
+
+// IsNone reports whether the constraints match no version. This is the case
+// for NoneConstraint and for constraints where each OR group bounds an empty
+// range, such as `>= 2.0.0, < 1.0.0`. A branch pin is reported by IsBranch
+// instead.
+func (cs Constraints) IsNone() bool {
+	if cs.branch != "" {
+		return false
+	}
+
+	for _, r := range cs.ranges() {
+		// Without a lower bound the range starts at the lowest version, so
+		// `< 0.0.0-0` is empty too.
+		if r.min == nil {
+			r.min, r.minIncl = &Version{pre: "0"}, true
+		}
+		if !r.isEmpty() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsAny reports whether the constraints match any version. This is the case
+// when one of the OR groups is only made of `*`, `latest` or an empty
+// constraint.
+func (cs Constraints) IsAny() bool {
+	for _, o := range cs.constraints {
+		joy := true
+		for _, c := range o {
+			if !c.matchAll {
 				joy = false
+				break
 			}
 		}
 
 		if joy {
-			return true, []error{}
+			return true
 		}
 	}
 
-	return false, e
+	return false
+}
+
+// ExactVersion returns the version the constraints pin, such as 1.2.3 for
+// `1.2.3` or `=1.2.3`. It returns false unless the constraints are a single
+// group of exact versions that are all equal, so ranges, wildcards like
+// `1.2.x` and OR alternatives like `1.2.3 || 1.3.0` are not pins.
+func (cs Constraints) ExactVersion() (*Version, bool) {
+	if cs.branch != "" || len(cs.constraints) != 1 {
+		return nil, false
+	}
+
+	var v *Version
+	for _, c := range cs.constraints[0] {
+		if !c.exact || (v != nil && !v.Equal(c.con)) {
+			return nil, false
+		}
+		v = c.con
+	}
+
+	return v, v != nil
+}
+
+// Exclusions returns the versions excluded with `!=`, such as 1.2.3 and 1.5.0
+// in `^1.0.0, != 1.2.3, != 1.5.0`, in the order they appear. A version
+// excluded in several OR groups is only returned once. Wildcard exclusions,
+// such as `!= 1.2.x`, exclude a range rather than a version and are skipped.
+func (cs Constraints) Exclusions() []*Version {
+	var vs []*Version
+	for _, o := range cs.constraints {
+		for _, c := range o {
+			if c.op != "!=" || c.dirty {
+				continue
+			}
+
+			seen := false
+			for _, v := range vs {
+				if v.Equal(c.con) {
+					seen = true
+					break
+				}
+			}
+			if !seen {
+				vs = append(vs, c.con)
+			}
+		}
+	}
+
+	return vs
 }
 
 var constraintOps map[string]cfunc
 var constraintMsg map[string]string
 var constraintRegex *regexp.Regexp
 
+// constraintOpRegex finds the operator characters leading a constraint.
+var constraintOpRegex = regexp.MustCompile(`^\s*([<>=!~^]{2,})`)
+
 func init() {
 	constraintOps = map[string]cfunc{
 		"":   constraintTildeOrEqual,
 		"=":  constraintTildeOrEqual,
 		"!=": constraintNotEqual,
+		"!":  constraintNotEqual,
 		">":  constraintGreaterThan,
 		"<":  constraintLessThan,
 		">=": constraintGreaterThanEqual,
@@ -100,6 +818,7 @@ func init() {
 		"=<": constraintLessThanEqual,
 		"~":  constraintTilde,
 		"~>": constraintTilde,
+		"~=": constraintTilde,
 		"^":  constraintCaret,
 	}
 
@@ -107,6 +826,7 @@ func init() {
 		"":   "%s is not equal to %s",
 		"=":  "%s is not equal to %s",
 		"!=": "%s is equal to %s",
+		"!":  "%s is equal to %s",
 		">":  "%s is less than or equal to %s",
 		"<":  "%s is greater than or equal to %s",
 		">=": "%s is less than %s",
@@ -115,6 +835,7 @@ func init() {
 		"=<": "%s is greater than %s",
 		"~":  "%s does not have same major and minor version as %s",
 		"~>": "%s does not have same major and minor version as %s",
+		"~=": "%s is not a compatible release of %s",
 		"^":  "%s does not have same major version as %s",
 	}
 
@@ -129,7 +850,7 @@ func init() {
 		cvRegex))
 
 	constraintRangeRegex = regexp.MustCompile(fmt.Sprintf(
-		`\s*(%s)\s+-\s+(%s)\s*`,
+		`^\s*(%s)\s+-\s+(%s)\s*$`,
 		cvRegex, cvRegex))
 }
 
@@ -141,6 +862,10 @@ type constraint struct {
 
 	msg string
 
+	// The operator of the constraint with aliases resolved. For example,
+	// both `=>` and `>=` are stored as `>=`.
+	op string
+
 	// The version used in the constraint check. For example, if a constraint
 	// is '<= 2.0.0' the con a version instance representing 2.0.0.
 	con *Version
@@ -152,18 +877,64 @@ type constraint struct {
 	minorDirty bool
 	dirty      bool
 	patchDirty bool
+
+	// When the constraint matches any version (e.g., * or latest)
+	matchAll bool
+
+	// When the constraint matches a single version (e.g., = 1.2.3)
+	exact bool
+}
+
+// String returns the constraint with its operator and its version as written.
+// The = operator is left out.
+func (c *constraint) String() string {
+	if c.matchAll || c.op == "=" {
+		return c.orig
+	}
+
+	return c.op + c.orig
 }
 
 // Check if a version meets the constraint
 func (c *constraint) check(v *Version) bool {
+	// Exact versions are the most common constraints. They are compared
+	// directly, skipping the constraint function. A version only equals the
+	// constraint when both have a pre-release or neither has one.
+	if c.exact {
+		o := c.con
+		return v.major == o.major && v.minor == o.minor && v.patch == o.patch &&
+			v.epoch == o.epoch && (v.pre == o.pre ||
+			(v.pre != "" && o.pre != "" && comparePrerelease(v.pre, o.pre) == 0))
+	}
+
 	return c.function(v, c)
 }
 
 type cfunc func(v *Version, c *constraint) bool
 
 func parseConstraint(c string) (*constraint, error) {
+	if isAny(c) {
+		cs := &constraint{
+			function: constraintAny,
+			msg:      "%s is a prerelease and does not match %s",
+			op:       "*",
+			con:      MustParse("0.0.0"),
+			orig:     strings.TrimSpace(c),
+			dirty:    true,
+			matchAll: true,
+		}
+		return cs, nil
+	}
+
 	m := constraintRegex.FindStringSubmatch(c)
 	if m == nil {
+		// Point out operators typed together, such as ~^1.2.3, rather than
+		// the whole constraint.
+		if op := constraintOpRegex.FindStringSubmatch(c); op != nil {
+			if _, ok := constraintOps[op[1]]; !ok {
+				return nil, fmt.Errorf("invalid operator combination %q in constraint: %s", op[1], strings.TrimSpace(c))
+			}
+		}
 		return nil, fmt.Errorf("improper constraint: %s", c)
 	}
 
@@ -172,7 +943,20 @@ func parseConstraint(c string) (*constraint, error) {
 	minorDirty := false
 	patchDirty := false
 	dirty := false
-	if isX(m[3]) {
+	if m[1] == "~=" {
+		// The ~= operator of PEP 440 needs at least a major and a minor
+		// version and does not take wildcards. When the patch version is
+		// left out the major version is kept (~=1.2 is >=1.2, <2.0),
+		// otherwise the minor version is (~=1.2.3 is >=1.2.3, <1.3.0).
+		if m[4] == "" || isX(m[3]) || isX(strings.TrimPrefix(m[4], ".")) ||
+			isX(strings.TrimPrefix(m[5], ".")) {
+			return nil, fmt.Errorf("improper constraint: %s", c)
+		}
+		if m[5] == "" {
+			minorDirty = true
+			dirty = true
+		}
+	} else if isX(m[3]) {
 		ver = "0.0.0"
 		dirty = true
 	} else if isX(strings.TrimPrefix(m[4], ".")) || m[4] == "" {
@@ -183,9 +967,14 @@ func parseConstraint(c string) (*constraint, error) {
 		dirty = true
 		patchDirty = true
 		ver = fmt.Sprintf("%s%s.0%s", m[3], m[4], m[6])
+	} else if m[1] == "^" && m[5] == "" {
+		// A caret without a patch version, such as ^0.0, keeps the minor
+		// version rather than the patch version.
+		dirty = true
+		patchDirty = true
 	}
 
-	con, err := NewVersion(ver)
+	con, err := parseVersion(ver, false)
 	if err != nil {
 
 		// The constraintRegex should catch any regex parsing errors. So,
@@ -196,16 +985,24 @@ func parseConstraint(c string) (*constraint, error) {
 	cs := &constraint{
 		function:   constraintOps[m[1]],
 		msg:        constraintMsg[m[1]],
+		op:         canonicalOp(m[1]),
 		con:        con,
 		orig:       orig,
 		minorDirty: minorDirty,
 		patchDirty: patchDirty,
 		dirty:      dirty,
+		exact:      canonicalOp(m[1]) == "=" && !dirty,
 	}
 	return cs, nil
 }
 
 // Constraint functions
+func constraintAny(v *Version, c *constraint) bool {
+	// Pre-releases are not matched by a constraint that doesn't look for
+	// them. See issue 21 for more details.
+	return v.Prerelease() == ""
+}
+
 func constraintNotEqual(v *Version, c *constraint) bool {
 	if c.dirty {
 
@@ -261,13 +1058,9 @@ func constraintLessThan(v *Version, c *constraint) bool {
 		return v.Compare(c.con) < 0
 	}
 
-	if v.Major() > c.con.Major() {
-		return false
-	} else if v.Minor() > c.con.Minor() && !c.minorDirty {
-		return false
-	}
-
-	return true
+	// A wildcard admits the whole line it stands for, so <1.4.x and <=1.4.x
+	// both admit 1.4.9 and are the same as <1.5.0.
+	return v.Compare(c.wildcardCeiling()) < 0
 }
 
 func constraintGreaterThanEqual(v *Version, c *constraint) bool {
@@ -299,13 +1092,9 @@ func constraintLessThanEqual(v *Version, c *constraint) bool {
 		return v.Compare(c.con) <= 0
 	}
 
-	if v.Major() > c.con.Major() {
-		return false
-	} else if v.Minor() > c.con.Minor() && !c.minorDirty {
-		return false
-	}
-
-	return true
+	// A wildcard admits the whole line it stands for, so <1.4.x and <=1.4.x
+	// both admit 1.4.9 and are the same as <1.5.0.
+	return v.Compare(c.wildcardCeiling()) < 0
 }
 
 // ~*, ~>* --> >= 0.0.0 (any)
@@ -333,7 +1122,8 @@ func constraintTilde(v *Version, c *constraint) bool {
 		return true
 	}
 
-	if v.Major() != c.con.Major() {
+	// A higher epoch is above the ceiling whatever the major version.
+	if v.Epoch() != c.con.Epoch() || v.Major() != c.con.Major() {
 		return false
 	}
 
@@ -368,6 +1158,10 @@ func constraintTildeOrEqual(v *Version, c *constraint) bool {
 // ^1.2, ^1.2.x --> >=1.2.0, <2.0.0
 // ^1.2.3 --> >=1.2.3, <2.0.0
 // ^1.2.0 --> >=1.2.0, <2.0.0
+// ^0, ^0.x --> >=0.0.0, <1.0.0
+// ^0.0, ^0.0.x --> >=0.0.0, <0.1.0
+// ^0.2, ^0.2.x, ^0.2.3 --> >=0.2.0, <0.3.0 (>=0.2.3 for ^0.2.3)
+// ^0.0.3 --> >=0.0.3, <0.0.4
 func constraintCaret(v *Version, c *constraint) bool {
 	// If there is a pre-release on the version but the constraint isn't looking
 	// for them assume that pre-releases are not compatible. See issue 21 for
@@ -376,20 +1170,49 @@ func constraintCaret(v *Version, c *constraint) bool {
 		return false
 	}
 
-	if v.LessThan(c.con) {
-		return false
+	if c.caretAny() {
+		return true
 	}
 
-	if v.Major() != c.con.Major() {
+	if v.LessThan(c.con) || v.Epoch() != c.con.Epoch() {
 		return false
 	}
 
-	return true
+	// Below 1.0.0 the first non zero segment given is the one that has to
+	// match, as it is where breaking changes are made.
+	switch {
+	case c.con.Major() > 0 || c.minorDirty:
+		return v.Major() == c.con.Major()
+	case c.con.Minor() > 0 || c.patchDirty:
+		return v.Major() == 0 && v.Minor() == c.con.Minor()
+	default:
+		return v.Major() == 0 && v.Minor() == 0 && v.Patch() == c.con.Patch()
+	}
+}
+
+// caretAny tells if a caret constraint admits any version, as ^* does.
+func (c *constraint) caretAny() bool {
+	return c.dirty && !c.minorDirty && !c.patchDirty
+}
+
+// caretCeiling returns the first version above the versions a caret
+// constraint admits, or nil when it admits any version.
+func (c *constraint) caretCeiling() *Version {
+	switch {
+	case c.caretAny():
+		return nil
+	case c.con.Major() > 0 || c.minorDirty:
+		return newVersion(c.con.Major()+1, 0, 0)
+	case c.con.Minor() > 0 || c.patchDirty:
+		return newVersion(0, c.con.Minor()+1, 0)
+	default:
+		return newVersion(0, 0, c.con.Patch()+1)
+	}
 }
 
 var constraintRangeRegex *regexp.Regexp
 
-const cvRegex string = `v?([0-9|x|X|\*]+)(\.[0-9|x|X|\*]+)?(\.[0-9|x|X|\*]+)?` +
+const cvRegex string = `[vV]?([0-9|x|X|\*]+)(\.[0-9|x|X|\*]+)?(\.[0-9|x|X|\*]+)?` +
 	`(-([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?` +
 	`(\+([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?`
 
@@ -402,18 +1225,107 @@ func isX(x string) bool {
 	}
 }
 
+// canonicalOp resolves the aliases of the constraint operators.
+func canonicalOp(op string) string {
+	switch op {
+	case "":
+		return "="
+	case "=>":
+		return ">="
+	case "=<":
+		return "<="
+	case "~>":
+		return "~"
+	case "!":
+		return "!="
+	default:
+		return op
+	}
+}
+
+// normalizeSpace replaces the Unicode white spaces of s with regular spaces.
+func normalizeSpace(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r != ' ' && unicode.IsSpace(r) {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+// isAny tells if a constraint is one of the tokens matching any version.
+func isAny(c string) bool {
+	switch strings.TrimSpace(c) {
+	case "*", "latest":
+		return true
+	default:
+		return false
+	}
+}
+
+// rewriteRange turns the hyphen ranges into comparisons. A range has to be a
+// whole constraint, with a version on each side of a spaced hyphen, so the
+// hyphen of a pre-release such as in 1.2.3-rc.1 - 2.0.0 is left alone.
 func rewriteRange(i string) string {
-	m := constraintRangeRegex.FindAllStringSubmatch(i, -1)
-	if m == nil {
-		return i
+	ors := strings.Split(i, "||")
+	for k, o := range ors {
+		ands := strings.Split(o, ",")
+		for j, a := range ands {
+			if v := constraintRangeRegex.FindStringSubmatch(a); v != nil {
+				ands[j] = rangeComparisons(v)
+			}
+		}
+		ors[k] = strings.Join(ands, ",")
 	}
-	o := i
-	for _, v := range m {
-		t := fmt.Sprintf(">= %s, <= %s", v[1], v[11])
-		o = strings.Replace(o, v[0], t, 1)
+
+	return strings.Join(ors, "||")
+}
+
+// rangeComparisons returns the comparisons standing for a hyphen range
+// matched by constraintRangeRegex.
+func rangeComparisons(v []string) string {
+	// A wildcard on the lower end starts the range at the lowest version it
+	// matches and one on the upper end ends it before the next minor or major
+	// version, so 1.2.* - 2.0.* is >= 1.2.0, < 2.1.0.
+	low, up := v[1], "<= "+v[11]
+	if floor, _, ok := wildcardRangeEnd(v[2], v[3], v[4]); ok {
+		low = floor
+	}
+	if _, ceiling, ok := wildcardRangeEnd(v[12], v[13], v[14]); ok {
+		up = "< " + ceiling
+	}
+
+	if up == "< " {
+		return ">= " + low
+	}
+	return fmt.Sprintf(">= %s, %s", low, up)
+}
+
+// wildcardRangeEnd returns the lowest version matched by an end of a hyphen
+// range holding a wildcard and the first version above the ones it matches.
+// The ceiling is empty when the wildcard matches every version.
+func wildcardRangeEnd(major, minor, patch string) (floor, ceiling string, ok bool) {
+	minor = strings.TrimPrefix(minor, ".")
+	patch = strings.TrimPrefix(patch, ".")
+
+	switch {
+	case isX(major):
+		return "0.0.0", "", true
+	case isX(minor):
+		n, err := strconv.ParseInt(major, 10, 64)
+		if err != nil {
+			return "", "", false
+		}
+		return major + ".0.0", fmt.Sprintf("%d.0.0", n+1), true
+	case isX(patch):
+		n, err := strconv.ParseInt(minor, 10, 64)
+		if err != nil {
+			return "", "", false
+		}
+		return major + "." + minor + ".0", fmt.Sprintf("%s.%d.0", major, n+1), true
 	}
 
-	return o
+	return "", "", false
 }
 
 // Detect if a version is not zero (0.0.0)
@@ -1,7 +1,9 @@
 package semver
 
 import (
+	"encoding/json"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -21,6 +23,8 @@ func TestParseConstraint(t *testing.T) {
 		{"v1.2", constraintTildeOrEqual, "1.2.0", false},
 		{"=1.5", constraintTildeOrEqual, "1.5.0", false},
 		{"> 1.3", constraintGreaterThan, "1.3.0", false},
+		{"!1.3", constraintNotEqual, "1.3.0", false},
+		{"!= 1.3", constraintNotEqual, "1.3.0", false},
 		{"< 1.4.1", constraintLessThan, "1.4.1", false},
 	}
 
@@ -50,6 +54,50 @@ func TestParseConstraint(t *testing.T) {
 	}
 }
 
+func TestParseConstraintWildcards(t *testing.T) {
+	tests := []struct {
+		in   string
+		node string
+	}{
+		{"1.2.%s", ">=1.2.0 <1.3.0"},
+		{"1.%s", ">=1.0.0 <2.0.0"},
+		{"1.%s.%s", ">=1.0.0 <2.0.0"},
+		{"^1.2.%s", ">=1.2.0 <2.0.0"},
+		{"^1.%s", ">=1.0.0 <2.0.0"},
+		{"^1.%s.%s", ">=1.0.0 <2.0.0"},
+		{"~1.2.%s", ">=1.2.0 <1.3.0"},
+		{"~1.%s", ">=1.0.0 <2.0.0"},
+		{"~1.%s.%s", ">=1.0.0 <2.0.0"},
+	}
+
+	versions := []string{"0.9.9", "1.0.0", "1.1.5", "1.2.0", "1.2.7", "1.3.0", "1.9.0", "2.0.0", "1.2.1-beta"}
+	for _, tc := range tests {
+		base, err := NewConstraint(strings.Replace(tc.in, "%s", "x", -1))
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		for _, w := range []string{"x", "X", "*"} {
+			in := strings.Replace(tc.in, "%s", w, -1)
+			c, err := NewConstraint(in)
+			if err != nil {
+				t.Errorf("err: %s", err)
+				continue
+			}
+
+			if a := c.ToNodeSemver(); a != tc.node {
+				t.Errorf("Expected %q to expand to %q but got %q", in, tc.node, a)
+			}
+			for _, v := range versions {
+				if c.Check(MustParse(v)) != base.Check(MustParse(v)) {
+					t.Errorf("Expected %q and %q to agree on %s", in, tc.in, v)
+				}
+			}
+		}
+	}
+}
+
 func TestConstraintCheck(t *testing.T) {
 	tests := []struct {
 		constraint string
@@ -115,6 +163,19 @@ func TestNewConstraint(t *testing.T) {
 
 		// The 3 - 4 should be broken into 2 by the range rewriting
 		{"3 - 4 || => 3.0, < 4", 2, 2, false},
+
+		{"latest", 1, 1, false},
+		{"", 1, 1, false},
+		{"~=1.2", 1, 1, false},
+		{"~=1.2.3", 1, 1, false},
+		{"~=2", 0, 0, true},
+		{"~=1.x", 0, 0, true},
+		{"~=1.2.*", 0, 0, true},
+
+		// Unicode spaces are handled as regular spaces.
+		{">=\u00a01.2.3", 1, 1, false},
+		{">= 1.2.3,\t< 2.0 ||\u2003~3.1\u00a0", 2, 2, false},
+		{"1.0\u00a0-\u00a02.0", 1, 2, false},
 	}
 
 	for _, tc := range tests {
@@ -144,6 +205,647 @@ func TestNewConstraint(t *testing.T) {
 	}
 }
 
+func TestNewConstraintWithOptions(t *testing.T) {
+	tests := []struct {
+		input string
+		err   bool
+	}{
+		{">=2.0.0, <1.0.0", true},
+		{">=1.0.0, <2.0.0", false},
+		{">1.2.3, <=1.2.3", true},
+		{">=1.2.3, <=1.2.3", false},
+		{"^1.2.3, 2.x", true},
+		{"^1.2.3, 1.x", false},
+		{">=2.0.0, <1.0.0 || ^3.0.0", true},
+		{"!= 1.2.3, 1.2.3", false},
+		{"*", false},
+	}
+
+	opts := ConstraintOptions{ErrorOnEmpty: true}
+	for _, tc := range tests {
+		_, err := NewConstraintWithOptions(tc.input, opts)
+		if tc.err && err == nil {
+			t.Errorf("expected but did not get error for: %s", tc.input)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for input %s: %s", tc.input, err)
+		}
+
+		// Without the option the constraints are parsed leniently.
+		if _, err := NewConstraintWithOptions(tc.input, ConstraintOptions{}); err != nil {
+			t.Errorf("unexpected error for input %s: %s", tc.input, err)
+		}
+	}
+}
+
+func TestConstraintsString(t *testing.T) {
+	tests := []struct {
+		input string
+		str   string
+	}{
+		{"1.2.x", "1.2.x"},
+		{"=> 1.2.x || ~>2", ">=1.2.x || ~2"},
+		{"^1.X, != 1.4.*", "^1.X, !=1.4.*"},
+		{"v1.2.3-beta.1+build", "v1.2.3-beta.1+build"},
+		{"= 1.2.3", "1.2.3"},
+		{"! 1.2.3", "!=1.2.3"},
+		{"1 - 2.x", ">=1, <3.0.0"},
+		{"~=1.2", "~=1.2"},
+		{"=v1.2.3", "v1.2.3"},
+		{">= V2.0.0", ">=V2.0.0"},
+		{"~>v1.2", "~v1.2"},
+		{"latest || *", "latest || *"},
+		{"", "*"},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.input)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		a := c.String()
+		if a != tc.str {
+			t.Errorf("Expected %q to give %q but got %q", tc.input, tc.str, a)
+		}
+
+		c2, err := NewConstraint(a)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+		if a2 := c2.String(); a2 != a {
+			t.Errorf("Expected %q to parse back to %q but got %q", a, a, a2)
+		}
+		for _, v := range []string{"0.9.0", "1.0.0", "1.2.3", "1.2.4", "1.4.2", "2.0.0", "2.4.0", "3.0.0"} {
+			if c.Check(MustParse(v)) != c2.Check(MustParse(v)) {
+				t.Errorf("Expected %q and %q to agree on %s", tc.input, a, v)
+			}
+		}
+	}
+}
+
+func TestConstraintsTextMarshal(t *testing.T) {
+	type config struct {
+		Constraint *Constraints `json:"constraint"`
+	}
+
+	var cfg config
+	if err := json.Unmarshal([]byte(`{"constraint": "=> 1.2.x || ~>2"}`), &cfg); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if cfg.Constraint == nil || !cfg.Constraint.Check(MustParse("2.0.5")) {
+		t.Fatalf("Expected the decoded constraint to allow 2.0.5")
+	}
+
+	text, err := cfg.Constraint.MarshalText()
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if e := ">=1.2.x || ~2"; string(text) != e {
+		t.Errorf("Expected %s but got %s", e, text)
+	}
+
+	out, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	var cfg2 config
+	if err := json.Unmarshal(out, &cfg2); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if cfg2.Constraint.String() != cfg.Constraint.String() {
+		t.Errorf("Expected the round trip to give %q but got %q", cfg.Constraint, cfg2.Constraint)
+	}
+
+	if err := json.Unmarshal([]byte(`{"constraint": "~^1.2"}`), &cfg); err == nil {
+		t.Error("Expected an error for an improper constraint")
+	}
+
+	branch, err := NewConstraintWithOptions("main", ConstraintOptions{AllowBranchTokens: []string{"main"}})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := json.Marshal(config{branch}); err == nil {
+		t.Error("Expected an error marshaling a branch constraint")
+	}
+}
+
+func TestNoneConstraint(t *testing.T) {
+	c := NoneConstraint()
+	for _, v := range []string{"0.0.0", "0.0.0-0", "1.2.3", "1.2.3-beta"} {
+		if c.Check(MustParse(v)) {
+			t.Errorf("Expected %s to not satisfy the none constraint", v)
+		}
+	}
+	if !c.IsNone() {
+		t.Error("Expected the none constraint to be none")
+	}
+
+	c2, err := NewConstraint(c.String())
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !c2.IsNone() || c2.Check(MustParse("0.0.0")) {
+		t.Errorf("Expected %q to match no version", c.String())
+	}
+}
+
+func TestConstraintsIsNone(t *testing.T) {
+	tests := []struct {
+		constraint string
+		none       bool
+	}{
+		{">= 2.0.0, < 1.0.0", true},
+		{">= 2.0.0, < 1.0.0 || > 3.0.0, < 3.0.0", true},
+		{"< 0.0.0-0", true},
+		{">= 2.0.0, < 1.0.0 || ^1.2.3", false},
+		{"^1.2.3", false},
+		{"!= 1.2.3", false},
+		{"*", false},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+		if a := c.IsNone(); a != tc.none {
+			t.Errorf("Expected %q none to be %t", tc.constraint, tc.none)
+		}
+	}
+
+	c, err := NewConstraintWithOptions("main", ConstraintOptions{AllowBranchTokens: []string{"main"}})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if c.IsNone() {
+		t.Error("Expected a branch pin to not be none")
+	}
+}
+
+func TestConstraintsIsBranch(t *testing.T) {
+	opts := ConstraintOptions{AllowBranchTokens: []string{"main", "develop"}}
+
+	c, err := NewConstraintWithOptions(" main ", opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if b, ok := c.IsBranch(); !ok || b != "main" {
+		t.Errorf("Expected a main branch pin but got %q, %t", b, ok)
+	}
+	if c.Check(MustParse("1.0.0")) {
+		t.Error("Expected a branch pin to match no version")
+	}
+	if c.String() != "main" {
+		t.Errorf("Expected main but got %q", c.String())
+	}
+
+	c, err = NewConstraintWithOptions("^1.2.0", opts)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, ok := c.IsBranch(); ok {
+		t.Error("Expected a version constraint not to be a branch pin")
+	}
+	if !c.Check(MustParse("1.3.0")) {
+		t.Error("Expected the version constraint to be checked")
+	}
+
+	// Tokens are only recognized when allowed.
+	if _, err := NewConstraint("main"); err == nil {
+		t.Error("Expected an error for a branch token that is not allowed")
+	}
+	if _, err := NewConstraintWithOptions("feature", opts); err == nil {
+		t.Error("Expected an error for a branch token that is not allowed")
+	}
+}
+
+func TestUnionConstraints(t *testing.T) {
+	var cs []*Constraints
+	for _, s := range []string{"^1.2.0", "~2.1.0 || 3.0.0", "!= 4.0.0, >= 4.0.0, < 5.0.0"} {
+		c, err := NewConstraint(s)
+		if err != nil {
+			t.Fatalf("err: %s", err)
+		}
+		cs = append(cs, c)
+	}
+
+	u, err := UnionConstraints(cs...)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if len(u.constraints) != 4 {
+		t.Errorf("Expected 4 OR groups but got %d", len(u.constraints))
+	}
+
+	for _, s := range []string{"1.1.0", "1.5.0", "2.1.3", "2.2.0", "3.0.0", "4.0.0", "4.0.1", "5.0.0", "1.5.0-beta"} {
+		v := MustParse(s)
+		e := false
+		for _, c := range cs {
+			e = e || c.Check(v)
+		}
+		if a := u.Check(v); a != e {
+			t.Errorf("Expected the union to give %t for %s but got %t", e, s, a)
+		}
+	}
+
+	if u, err := UnionConstraints(); err != nil || u.Check(MustParse("1.0.0")) {
+		t.Error("Expected an empty union to match no version")
+	}
+
+	floor := ConstraintOptions{Floor: MustParse("1.0.0")}
+	a, err := NewConstraintWithOptions("^0.9.0", floor)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	b, err := NewConstraintWithOptions("^1.2.0", floor)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	u, err = UnionConstraints(a, b)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if u.Check(MustParse("0.9.5")) || !u.Check(MustParse("1.2.3")) {
+		t.Error("Expected the union to keep the floor of the constraints")
+	}
+	if _, err := UnionConstraints(a, cs[0]); err == nil {
+		t.Error("Expected an error for constraints with different floors")
+	}
+
+	branch, err := NewConstraintWithOptions("main", ConstraintOptions{AllowBranchTokens: []string{"main"}})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if _, err := UnionConstraints(branch, cs[0]); err == nil {
+		t.Error("Expected an error for a branch constraint")
+	}
+	if _, err := UnionConstraints(cs[0], nil); err == nil {
+		t.Error("Expected an error for nil constraints")
+	}
+
+	w, err := NewConstraint("1.2.3, 1.4.5")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if u, err := UnionConstraints(cs[0], w); err != nil || !reflect.DeepEqual(u.Warnings(), w.Warnings()) {
+		t.Errorf("Expected the union to keep the warnings %v", w.Warnings())
+	}
+}
+
+func TestConstraintOperatorCombination(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{"~^1.2.3", `invalid operator combination "~^" in constraint: ~^1.2.3`},
+		{"^~1.2.3", `invalid operator combination "^~" in constraint: ^~1.2.3`},
+		{"^>1.0.0", `invalid operator combination "^>" in constraint: ^>1.0.0`},
+		{">= 1.0, <<2.0", `invalid operator combination "<<" in constraint: <<2.0`},
+		{"~>=1.2", `invalid operator combination "~>=" in constraint: ~>=1.2`},
+		{">=foo", "improper constraint: >=foo"},
+		{"^^1.2.3", `invalid operator combination "^^" in constraint: ^^1.2.3`},
+		{"=> 1.2.3", ""},
+	}
+
+	for _, tc := range tests {
+		_, err := NewConstraint(tc.input)
+		if tc.err == "" {
+			if err != nil {
+				t.Errorf("unexpected error for input %s: %s", tc.input, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("expected but did not get error for: %s", tc.input)
+			continue
+		}
+		if err.Error() != tc.err {
+			t.Errorf("Expected error %q for %s but got %q", tc.err, tc.input, err)
+		}
+	}
+}
+
+func TestAllowFloorPrereleases(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		check      bool
+	}{
+		{"^1.2.0", "1.2.0-beta.3", true},
+		{"^1.2.0", "1.2.0", true},
+		{"^1.2.0", "1.5.0-beta", false},
+		{"^1.2.0", "1.1.0-beta", false},
+		{"^1.2.0", "1.2.0-beta.3+build.1", true},
+		{">= 1.2.0, < 2.0.0", "1.2.0-rc.1", true},
+		{"> 1.2.0", "1.2.0-rc.1", false},
+		{"~1.2.3 || ^2.0.0", "2.0.0-alpha", true},
+		{"~1.2.3 || ^2.0.0", "1.2.3-alpha", true},
+		{"~1.2.3 || ^2.0.0", "1.2.4-alpha", false},
+		{"^1.2.0, != 1.2.0", "1.2.0-beta", false},
+		{"^1.2.0-beta", "1.2.0-alpha", false},
+		{"^1.2.0, != 1.2.0-beta.3", "1.2.0-beta.3", false},
+		{"^1.2.0, != 1.2.0-beta.3", "1.2.0-beta.4", true},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraintWithOptions(tc.constraint, ConstraintOptions{AllowFloorPrereleases: true})
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		v := MustParse(tc.version)
+		if a := c.Check(v); a != tc.check {
+			t.Errorf("Constraint %q failing with %q", tc.constraint, tc.version)
+		}
+		if a, _ := c.Validate(v); a != tc.check {
+			t.Errorf("Constraint %q failing to validate %q", tc.constraint, tc.version)
+		}
+		if a, _ := c.CheckReason(v); a != tc.check {
+			t.Errorf("Constraint %q failing to give the reason for %q", tc.constraint, tc.version)
+		}
+	}
+
+	c, _ := NewConstraint("^1.2.0")
+	if c.Check(MustParse("1.2.0-beta.3")) {
+		t.Error("Expected 1.2.0-beta.3 to not satisfy ^1.2.0 without the option")
+	}
+}
+
+func TestSameCorePrereleases(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		check      bool
+	}{
+		{">1.2.3-alpha.3", "1.2.3-alpha.7", true},
+		{">1.2.3-alpha.3", "1.2.3", true},
+		{">1.2.3-alpha.3", "3.4.5", true},
+		{">1.2.3-alpha.3", "3.4.5-alpha.9", false},
+		{"<1.2.3-beta", "1.2.2-alpha", false},
+		{"<1.2.3-beta", "1.2.3-alpha", true},
+		{">=1.0.0-0, <1.0.1-0", "1.0.0-alpha", true},
+		{">=1.2.3-alpha, <2.0.0", "1.5.0-beta", false},
+		{"^1.2.3-beta", "1.2.3-rc", true},
+		{"^1.2.3-beta", "1.5.0-rc", false},
+		{">1.2.3", "1.2.4-alpha", false},
+		{">1.2.3-alpha || >=1.5.0-0", "1.5.0-rc", true},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraintWithOptions(tc.constraint, ConstraintOptions{SameCorePrereleases: true})
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		v := MustParse(tc.version)
+		if a := c.Check(v); a != tc.check {
+			t.Errorf("Constraint %q failing with %q", tc.constraint, tc.version)
+		}
+		if a, _ := c.Validate(v); a != tc.check {
+			t.Errorf("Constraint %q failing to validate %q", tc.constraint, tc.version)
+		}
+		if a, _ := c.CheckInstrumented(v); a != tc.check {
+			t.Errorf("Constraint %q failing to check %q instrumented", tc.constraint, tc.version)
+		}
+		if a, r := c.CheckReason(v); a != tc.check || (!a && r != ReasonPrereleaseExcluded) {
+			t.Errorf("Constraint %q giving the reason %s for %q", tc.constraint, r, tc.version)
+		}
+	}
+
+	c, _ := NewConstraint(">1.2.3-alpha.3")
+	if !c.Check(MustParse("3.4.5-alpha.9")) {
+		t.Error("Expected the pre-releases of other versions to be evaluated without the option")
+	}
+}
+
+func TestCasefoldPrerelease(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		check      bool
+		casefold   bool
+	}{
+		{"1.2.0-rc.1", "1.2.0-RC.1", false, true},
+		{"1.2.0-RC.1", "1.2.0-rc.1", false, true},
+		{"1.2.0-rc.1", "1.2.0-rc.1", true, true},
+		{"1.2.0-rc.1", "1.2.0-RC.2", false, false},
+		{">= 1.2.0-rc.1, <= 1.2.0-rc.5", "1.2.0-RC.2", false, true},
+		{">= 1.2.0-beta.1, <= 1.2.0-rc.5", "1.2.0-RC.2", false, true},
+		{"1.2.0-rc.1 || 1.3.0", "1.2.0-Rc.1", false, true},
+		{"^1.2.0", "1.2.0", true, true},
+	}
+
+	for _, tc := range tests {
+		v := MustParse(tc.version)
+
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+		if a := c.Check(v); a != tc.check {
+			t.Errorf("Constraint %q with version %q expected %t", tc.constraint, tc.version, tc.check)
+		}
+
+		c, err = NewConstraintWithOptions(tc.constraint, ConstraintOptions{CasefoldPrerelease: true})
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+		if a := c.Check(v); a != tc.casefold {
+			t.Errorf("Casefold constraint %q with version %q expected %t", tc.constraint, tc.version, tc.casefold)
+		}
+		if a, _ := c.Validate(v); a != tc.casefold {
+			t.Errorf("Casefold constraint %q validating version %q expected %t", tc.constraint, tc.version, tc.casefold)
+		}
+		if a, _ := c.CheckReason(v); a != tc.casefold {
+			t.Errorf("Casefold constraint %q giving the reason for version %q expected %t", tc.constraint, tc.version, tc.casefold)
+		}
+	}
+
+	c, _ := NewConstraintWithOptions("^1.2.0-rc.1, != 1.2.0-RC.2", ConstraintOptions{CasefoldPrerelease: true})
+	if _, ok := c.CheckExcluded(MustParse("1.2.0-RC.3")); ok {
+		t.Error("Expected a casefold match not to be reported as excluded")
+	}
+
+	u, err := UnionConstraints(c, c)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if !u.Check(MustParse("1.2.0-RC.3")) {
+		t.Error("Expected the union to keep the casefold option")
+	}
+	plain, _ := NewConstraint("^1.2.0")
+	if _, err := UnionConstraints(c, plain); err == nil {
+		t.Error("Expected an error for constraints parsed with different options")
+	}
+}
+
+func TestConstraintOptionsFloor(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		check      bool
+	}{
+		{"^0.9.0", "0.9.0", false},
+		{"^0.9.0", "0.9.5", false},
+		{"^1.2.0", "1.2.0", true},
+		{">= 0.5.0", "0.8.0", false},
+		{">= 0.5.0", "1.0.0", true},
+		{">= 0.5.0", "1.0.0-beta", false},
+		{">= 1.0.0-0", "1.0.0-beta", false},
+		{"^0.9.0 || ^1.0.0", "0.9.5", false},
+		{"^0.9.0 || ^1.0.0", "1.1.0", true},
+		{"0.9.0 || 0.9.1", "0.9.1", false},
+		{"*", "0.1.0", false},
+		{"*", "1.0.0", true},
+	}
+
+	floor := MustParse("1.0.0")
+	for _, tc := range tests {
+		c, err := NewConstraintWithOptions(tc.constraint, ConstraintOptions{Floor: floor})
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		v := MustParse(tc.version)
+		if a := c.Check(v); a != tc.check {
+			t.Errorf("Constraint %q with floor %s and version %q expected %t", tc.constraint, floor, tc.version, tc.check)
+		}
+		if a, _ := c.Validate(v); a != tc.check {
+			t.Errorf("Constraint %q with floor %s validating version %q expected %t", tc.constraint, floor, tc.version, tc.check)
+		}
+		if a, _ := c.CheckReason(v); a != tc.check {
+			t.Errorf("Constraint %q with floor %s giving the reason for version %q expected %t", tc.constraint, floor, tc.version, tc.check)
+		}
+	}
+
+	below, _ := NewConstraintWithOptions("^0.9.0", ConstraintOptions{Floor: floor})
+	if !below.IsNone() {
+		t.Error("Expected a constraint below the floor to match nothing")
+	}
+	if r := below.Clamp(MustParse("0.9.5")); r != nil {
+		t.Errorf("Expected no version to clamp to below the floor but got %s", r)
+	}
+	if _, ok := below.CheckExcluded(MustParse("0.9.5")); ok {
+		t.Error("Expected a version below the floor not to be reported as excluded")
+	}
+
+	above, _ := NewConstraintWithOptions(">= 0.5.0", ConstraintOptions{Floor: floor})
+	if r := above.Clamp(MustParse("0.8.0")); r == nil || !r.Equal(floor) {
+		t.Errorf("Expected 0.8.0 to clamp to the floor but got %v", r)
+	}
+	if _, args := above.ToSQL("major", "minor", "patch"); !reflect.DeepEqual(args, []interface{}{int64(1), int64(1), int64(0), int64(1), int64(0), int64(0)}) {
+		t.Errorf("Expected the SQL predicate to compare to the floor but got %v", args)
+	}
+
+	c, _ := NewConstraintWithOptions("^0.9.0", ConstraintOptions{Floor: floor, AllowFloorPrereleases: true})
+	_, errs := c.Validate(MustParse("0.9.0-beta"))
+	if len(errs) == 0 || errs[0].Error() != "0.9.0-beta is below the floor 1.0.0" {
+		t.Errorf("Expected the floor to be reported first but got %v", errs)
+	}
+	if c.CapUpper(MustParse("2.0.0"), false).Check(MustParse("0.9.1")) {
+		t.Error("Expected a capped constraint to keep the floor")
+	}
+}
+
+func TestConstraintsCanonicalize(t *testing.T) {
+	tests := []struct {
+		constraints []string
+		canonical   string
+	}{
+		{
+			[]string{"<2.0.0, >=1.0.0 || ^0.5.0", "^0.5.0 || >=1.0.0, <2.0.0", ">= 1.0.0, < 2.0.0 || ^0.5.0"},
+			"^0.5.0 || >=1.0.0, <2.0.0",
+		},
+		{
+			[]string{"!=1.5.0, ^1.2.0, <1.8.0", "<1.8.0, !=1.5.0, ^1.2.0", "^1.2.0, <1.8.0, !=1.5.0"},
+			"^1.2.0, <1.8.0, !=1.5.0",
+		},
+		{
+			[]string{"1.3.0 || 1.1.0 || 1.2.0", "1.2.0 || 1.3.0 || 1.1.0"},
+			"1.1.0 || 1.2.0 || 1.3.0",
+		},
+		{
+			[]string{"> 1.0.0 || >= 1.0.0", ">= 1.0.0 || > 1.0.0"},
+			">=1.0.0 || >1.0.0",
+		},
+		{
+			[]string{"!=1.2.x, != 1.2.0, >=1.0.0 || <0.5.0", "<0.5.0 || >=1.0.0, !=1.2.0, !=1.2.x"},
+			"<0.5.0 || >=1.0.0, !=1.2.0, !=1.2.x",
+		},
+		{
+			[]string{"^1.0.0"},
+			"^1.0.0",
+		},
+	}
+
+	for _, tc := range tests {
+		for _, s := range tc.constraints {
+			c, err := NewConstraint(s)
+			if err != nil {
+				t.Errorf("err: %s", err)
+				continue
+			}
+
+			cc := c.Canonicalize()
+			if a := cc.String(); a != tc.canonical {
+				t.Errorf("Expected %q to canonicalize to %q but got %q", s, tc.canonical, a)
+			}
+			for _, v := range []string{"0.4.0", "0.5.5", "1.0.0", "1.1.0", "1.2.0", "1.2.5", "1.5.0", "1.9.0", "2.0.0"} {
+				if c.Check(MustParse(v)) != cc.Check(MustParse(v)) {
+					t.Errorf("Expected %q to check %s like its canonical form", s, v)
+				}
+			}
+		}
+	}
+
+	c, _ := NewConstraint("<2.0.0, >=1.0.0")
+	c.Canonicalize()
+	if c.String() != "<2.0.0, >=1.0.0" {
+		t.Errorf("Expected the constraints to be left as they are but got %q", c)
+	}
+}
+
+func TestConstraintsWarnings(t *testing.T) {
+	tests := []struct {
+		input    string
+		warnings []string
+	}{
+		{"1.2.3, 1.4.5", []string{"conflicting exact versions 1.2.3 and 1.4.5"}},
+		{"^1.0.0 || =1.2.3, v1.4.5, 1.2.3", []string{"conflicting exact versions 1.2.3 and v1.4.5"}},
+		{"1.2.3, v1.2.3", nil},
+		{"1.2.3 || 1.4.5", nil},
+		{"1.2.x, 1.4.5", nil},
+		{">= 1.2.3, < 1.4.5", nil},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.input)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+		if a := c.Warnings(); !reflect.DeepEqual(a, tc.warnings) {
+			t.Errorf("Expected %q to warn %v but got %v", tc.input, tc.warnings, a)
+		}
+
+		_, err = NewConstraintWithOptions(tc.input, ConstraintOptions{Strict: true})
+		if len(tc.warnings) > 0 && err == nil {
+			t.Errorf("expected but did not get error for: %s", tc.input)
+		} else if len(tc.warnings) == 0 && err != nil {
+			t.Errorf("unexpected error for input %s: %s", tc.input, err)
+		}
+	}
+}
+
 func TestConstraintsCheck(t *testing.T) {
 	tests := []struct {
 		constraint string
@@ -151,12 +853,25 @@ func TestConstraintsCheck(t *testing.T) {
 		check      bool
 	}{
 		{"*", "1.2.3", true},
+		{"*", "1.2.3-beta", false},
+		{"latest", "1.2.3", true},
+		{"latest", "0.0.0", true},
+		{"", "4.5.6", true},
 		{"~0.0.0", "1.2.3", true},
 		{"0.x.x", "1.2.3", false},
 		{"0.0.x", "1.2.3", false},
 		{"0.0.0", "1.2.3", false},
 		{"*", "1.2.3", true},
 		{"^0.0.0", "1.2.3", false},
+		{"^0.2.3", "0.2.5", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.2", "0.2.0", true},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0.0", "0.0.9", true},
+		{"^0.0", "0.1.0", false},
+		{"^0.x", "0.9.0", true},
+		{"^0.x", "1.0.0", false},
 		{"= 2.0", "1.2.3", false},
 		{"= 2.0", "2.0.0", true},
 		{"4.1", "4.1.0", true},
@@ -170,6 +885,16 @@ func TestConstraintsCheck(t *testing.T) {
 		{"!=4.x", "4.1.0", false},
 		{"!=4.1.x", "4.2.0", true},
 		{"!=4.2.x", "4.2.3", false},
+		{"!4.1", "4.1.0", false},
+		{"!4.1", "4.1.1", true},
+		{"! 4.1.0", "4.1.0", false},
+		{"!1.2.x", "1.2.0", false},
+		{"!1.2.x", "1.2.9", false},
+		{"!1.2.x", "1.1.9", true},
+		{"!1.2.x", "1.3.0", true},
+		{"!1.x", "1.3.0", false},
+		{"!1.x", "2.0.0", true},
+		{">=1.0, !1.2.x", "1.5.0", true},
 		{">1.1", "4.1.0", true},
 		{">1.1", "1.1.0", false},
 		{"<1.1", "0.1.0", true},
@@ -192,6 +917,16 @@ func TestConstraintsCheck(t *testing.T) {
 		{"<=2.x", "3.1.0", false},
 		{"<=1.1", "1.1.1", false},
 		{"<=1.1.x", "1.2.500", false},
+		{"<=1.4.x", "1.4.9", true},
+		{"<=1.4.x", "1.5.0", false},
+		{"<=1.4.x", "0.9.0", true},
+		{"<1.4.x", "1.4.9", true},
+		{"<1.4.x", "1.5.0", false},
+		{"<1.4.x", "0.9.0", true},
+		{"<=1.x", "1.9.9", true},
+		{"<=1.x", "2.0.0", false},
+		{"<1.x", "1.9.9", true},
+		{"<1.x", "2.0.0", false},
 		{">1.1, <2", "1.1.1", true},
 		{">1.1, <3", "4.3.2", false},
 		{">=1.1, <2, !=1.2.3", "1.2.3", false},
@@ -203,6 +938,25 @@ func TestConstraintsCheck(t *testing.T) {
 		{"1.1-3", "4.3.2", false},
 		{"^1.1", "1.1.1", true},
 		{"^1.1", "4.3.2", false},
+
+		// Pre-releases at the boundary of > and <, following node-semver
+		{">1.2.3-alpha.3", "1.2.3-alpha.7", true},
+		{">1.2.3-alpha.3", "1.2.3-alpha.3", false},
+		{">1.2.3-alpha.3", "1.2.3-alpha.2", false},
+		{">1.2.3-alpha.3", "1.2.3", true},
+		{">1.2.3-alpha.3", "3.4.5", true},
+		{">=1.2.3-alpha.3", "1.2.3-alpha.3", true},
+		{">1.2.3", "1.2.3-alpha", false},
+		{">1.2.3", "1.2.4-alpha", false},
+		{">=1.2.3", "1.2.3-alpha", false},
+		{"<1.2.3", "1.2.3-alpha", false},
+		{"<1.2.3", "1.2.2-alpha", false},
+		{"<=1.2.3", "1.2.3-alpha", false},
+		{"<1.2.3-beta", "1.2.3-alpha", true},
+		{"<1.2.3-beta", "1.2.3-beta", false},
+		{"<1.2.3-beta", "1.2.3-rc", false},
+		{"<=1.2.3-beta", "1.2.3-beta", true},
+
 		{"^1.x", "1.1.1", true},
 		{"^2.x", "1.1.1", false},
 		{"^1.x", "2.1.1", false},
@@ -226,6 +980,56 @@ func TestConstraintsCheck(t *testing.T) {
 		{"~1.2.3", "1.3.2", false},
 		{"~1.1", "1.2.3", false},
 		{"~1.3", "2.4.5", false},
+		{"~=1.2", "1.2.0", true},
+		{"~=1.2", "1.9.3", true},
+		{"~=1.2", "1.1.9", false},
+		{"~=1.2", "2.0.0", false},
+		{"~=1.2", "1.5.0-beta", false},
+		{"~=1.2.3", "1.2.3", true},
+		{"~=1.2.3", "1.2.9", true},
+		{"~=1.2.3", "1.2.2", false},
+		{"~=1.2.3", "1.3.0", false},
+		{"~= 2.2", "2.5.1", true},
+		{"~= 2.2", "3.0.0", false},
+		{"~=1.4.5, != 1.4.7", "1.4.7", false},
+		{"~=1.4.5, != 1.4.7", "1.4.8", true},
+		{"=v1.2.3", "1.2.3", true},
+		{"=v1.2.3", "1.2.4", false},
+		{">=v2.0.0", "2.0.0", true},
+		{">=v2.0.0", "1.9.9", false},
+		{"<v3.0.0", "2.9.9", true},
+		{"<v3.0.0", "3.0.0", false},
+		{"~v1.2", "1.2.9", true},
+		{"~v1.2", "1.3.0", false},
+		{"^V1.2.3", "1.9.0", true},
+		{"!= v1.2.x", "1.2.5", false},
+		{"v1.2.3 - V2.0.0", "2.0.0", true},
+		{"1.2.3-rc.1 - 2.0.0", "1.2.3", true},
+		{"1.2.3-rc.1 - 2.0.0", "1.2.2", false},
+		{"1.2.3-rc.1 - 2.0.0", "1.5.0", true},
+		{"1.2.3-rc.1 - 2.0.0", "2.0.1", false},
+		{"1.2.* - 2.0.*", "1.2.0", true},
+		{"1.2.* - 2.0.*", "1.1.9", false},
+		{"1.2.* - 2.0.*", "2.0.9", true},
+		{"1.2.* - 2.0.*", "2.1.0", false},
+		{"1.2.x - 2.0.0", "1.2.0", true},
+		{"1.2.x - 2.0.0", "2.0.1", false},
+		{"1.2.0 - 2.0.x", "1.5.0", true},
+		{"1.2.0 - 2.0.x", "2.0.7", true},
+		{"1.2.0 - 2.0.x", "2.1.0", false},
+		{"1.0.0 || =1.0.1 || 1.2.0", "1.0.1", true},
+		{"1.0.0 || =1.0.1 || 1.2.0", "1.1.0", false},
+		{"1.0.0 || 1.0.1-beta.1 || 1.2.0", "1.0.1-beta.1", true},
+		{"1.0.0 || 1.0.1-beta.1 || 1.2.0", "1.0.1", false},
+		{"1.0.0 || 1.0.1 || 1.2.0", "1.0.1-beta.1", false},
+		{"1.0.0 || 1.0.1 || 1.2.0", "1.0.1+build.3", true},
+		{"1.0.0 || 1.x", "1.5.0", true},
+		{"^1.2.0", "1:1.5.0", false},
+		{">=1.2.0, <2.0.0", "1:1.5.0", false},
+		{"~1.2.0", "1:1.2.5", false},
+		{"~1", "1:1.2.5", false},
+		{"1.2.x", "1:1.2.5", false},
+		{">=1.2.0", "1:1.5.0", true},
 	}
 
 	for _, tc := range tests {
@@ -235,7 +1039,7 @@ func TestConstraintsCheck(t *testing.T) {
 			continue
 		}
 
-		v, err := NewVersion(tc.version)
+		v, err := NewVersionEpoch(tc.version)
 		if err != nil {
 			t.Errorf("err: %s", err)
 			continue
@@ -248,6 +1052,314 @@ func TestConstraintsCheck(t *testing.T) {
 	}
 }
 
+func TestConstraintsCheckInstrumented(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		check      bool
+		evaluated  int
+	}{
+		{"^1.0.0", "1.2.0", true, 1},
+		{">= 1.0.0, < 2.0.0, != 1.5.0", "1.2.0", true, 3},
+		{">= 1.0.0, < 2.0.0, != 1.5.0", "0.5.0", false, 1},
+		{">= 1.0.0, < 2.0.0, != 1.5.0", "2.5.0", false, 2},
+		{"^1.0.0 || ^2.0.0 || ^3.0.0", "1.5.0", true, 1},
+		{"^1.0.0 || ^2.0.0 || ^3.0.0", "3.5.0", true, 3},
+		{"^1.0.0 || ^2.0.0 || ^3.0.0", "4.0.0", false, 3},
+		{">= 1.0.0, < 1.5.0 || >= 2.0.0, < 3.0.0", "1.8.0", false, 3},
+		{"1.0.0 || 2.0.0 || 3.0.0", "2.0.0", true, 1},
+		{"1.0.0 || 2.0.0 || 3.0.0", "4.0.0", false, 0},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		v := MustParse(tc.version)
+		ok, evaluated := c.CheckInstrumented(v)
+		if ok != tc.check || ok != c.Check(v) {
+			t.Errorf("Constraint %q with %s: expected %t but got %t", tc.constraint, tc.version, tc.check, ok)
+		}
+		if evaluated != tc.evaluated {
+			t.Errorf("Constraint %q with %s: expected %d evaluations but got %d",
+				tc.constraint, tc.version, tc.evaluated, evaluated)
+		}
+	}
+}
+
+func TestConstraintsCheckAll(t *testing.T) {
+	c, err := NewConstraint("^1.2.0 || 3.0.0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	raw := []string{"1.2.0", "1.1.9", "1.9.9", "2.0.0", "3.0.0", "1.5.0-beta"}
+	vs := make([]*Version, len(raw))
+	for i, r := range raw {
+		vs[i] = MustParse(r)
+	}
+
+	a := c.CheckAll(vs)
+	e := []bool{true, false, true, false, true, false}
+	if !reflect.DeepEqual(a, e) {
+		t.Errorf("Expected %v but got %v", e, a)
+	}
+
+	if a := c.CheckAll(nil); len(a) != 0 {
+		t.Errorf("Expected no results but got %v", a)
+	}
+}
+
+func TestConstraintsNextLowerThan(t *testing.T) {
+	candidates := []*Version{}
+	for _, r := range []string{"1.4.0", "2.1.0", "1.2.0", "1.9.0-beta", "0.9.0", "1.8.5", "2.0.0", "1.8.5+build"} {
+		candidates = append(candidates, MustParse(r))
+	}
+
+	tests := []struct {
+		constraint string
+		version    string
+		expected   string
+	}{
+		{"^1.0.0", "1.9.0", "1.8.5"},
+		{"^1.0.0", "2.5.0", "1.8.5"},
+		{"^1.0.0", "1.8.5", "1.4.0"},
+		{"^1.0.0", "1.4.0", "1.2.0"},
+		{"^1.0.0", "1.2.0", ""},
+		{"^1.0.0 || ^2.0.0", "2.1.0", "2.0.0"},
+		{">= 1.9.0-0", "1.9.0", "1.9.0-beta"},
+		{"^1.0.0, != 1.8.5", "1.9.0", "1.4.0"},
+		{"*", "0.9.0", ""},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		a := c.NextLowerThan(MustParse(tc.version), candidates)
+		if tc.expected == "" {
+			if a != nil {
+				t.Errorf("Expected nothing lower than %s for %q but got %s", tc.version, tc.constraint, a)
+			}
+			continue
+		}
+		if a == nil || a.String() != tc.expected {
+			t.Errorf("Expected %s lower than %s for %q but got %v", tc.expected, tc.version, tc.constraint, a)
+		}
+	}
+
+	c, _ := NewConstraint("^1.0.0")
+	if a := c.NextLowerThan(MustParse("1.9.0"), candidates); a != candidates[5] {
+		t.Errorf("Expected the first of equal candidates but got %s", a.Original())
+	}
+	if a := c.NextLowerThan(MustParse("1.9.0"), nil); a != nil {
+		t.Errorf("Expected nil without candidates but got %s", a)
+	}
+}
+
+func TestMinimumSatisfying(t *testing.T) {
+	available := []*Version{}
+	for _, r := range []string{"1.4.0", "2.1.0", "1.2.0", "1.9.0-beta", "0.9.0", "1.8.5", "2.0.0", "1.2.0+build"} {
+		available = append(available, MustParse(r))
+	}
+
+	tests := []struct {
+		constraints []string
+		expected    string
+	}{
+		{[]string{"^1.0.0"}, "1.2.0"},
+		{[]string{"^1.0.0", ">= 1.3.0"}, "1.4.0"},
+		{[]string{"^1.0.0", ">= 1.3.0", "!= 1.4.0"}, "1.8.5"},
+		{[]string{">= 1.5.0", "< 3.0.0", "^2.0.0 || ~1.8.0"}, "1.8.5"},
+		{[]string{"^1.0.0", ">= 2.0.0"}, ""},
+		{[]string{"^1.0.0", "^2.0.0"}, ""},
+		{[]string{"^3.0.0"}, ""},
+		{[]string{">= 1.9.0-0, < 2.0.0-0"}, "1.9.0-beta"},
+		{nil, "0.9.0"},
+	}
+
+	for _, tc := range tests {
+		var cs []*Constraints
+		for _, s := range tc.constraints {
+			c, err := NewConstraint(s)
+			if err != nil {
+				t.Fatalf("err: %s", err)
+			}
+			cs = append(cs, c)
+		}
+
+		v, ok := MinimumSatisfying(available, cs...)
+		if tc.expected == "" {
+			if ok || v != nil {
+				t.Errorf("Expected nothing to satisfy %q but got %s", tc.constraints, v)
+			}
+			continue
+		}
+		if !ok || v.String() != tc.expected {
+			t.Errorf("Expected %s to satisfy %q but got %v", tc.expected, tc.constraints, v)
+		}
+	}
+
+	c, _ := NewConstraint("^1.0.0")
+	if v, _ := MinimumSatisfying(available, c); v != available[2] {
+		t.Errorf("Expected the first of equal versions but got %s", v.Original())
+	}
+	if _, ok := MinimumSatisfying(nil, c); ok {
+		t.Error("Expected nothing to satisfy the constraints without available versions")
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		version    string
+		constraint string
+		check      bool
+		err        bool
+	}{
+		{"1.2.3", "^1.0.0", true, false},
+		{"2.0.0", "^1.0.0", false, false},
+		{"foo", "^1.0.0", false, true},
+		{"1.2.3", ">= bar", false, true},
+	}
+
+	for _, tc := range tests {
+		a, err := Satisfies(tc.version, tc.constraint)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected error for %q and %q", tc.version, tc.constraint)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+		if a != tc.check {
+			t.Errorf("Constraint %q reports %t for %q but should be %t", tc.constraint, a, tc.version, tc.check)
+		}
+		if MustSatisfies(tc.version, tc.constraint) != tc.check {
+			t.Errorf("MustSatisfies does not agree with Satisfies for %q and %q", tc.version, tc.constraint)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected MustSatisfies to panic on a bad version")
+		}
+	}()
+	MustSatisfies("foo", "^1.0.0")
+}
+
+func TestConstraintsIsAny(t *testing.T) {
+	tests := []struct {
+		constraint string
+		any        bool
+	}{
+		{"*", true},
+		{"latest", true},
+		{" latest ", true},
+		{"", true},
+		{">= 1.2 || latest", true},
+		{"~*", false},
+		{"1.x", false},
+		{">= 0.0.0", false},
+		{"*, < 2", false},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		a := c.IsAny()
+		if a != tc.any {
+			t.Errorf("Constraint %q expected IsAny %t but got %t", tc.constraint, tc.any, a)
+		}
+	}
+}
+
+func TestConstraintsExclusions(t *testing.T) {
+	tests := []struct {
+		constraint string
+		exclusions []string
+	}{
+		{"^1.0.0, != 1.2.3, != 1.5.0", []string{"1.2.3", "1.5.0"}},
+		{"^1.0.0, !1.2.3 || ^2.0.0, != 2.1.0, != 1.2.3", []string{"1.2.3", "2.1.0"}},
+		{"^1.0.0, != 1.2.x, != 1.4.0", []string{"1.4.0"}},
+		{"!= 1.2.3-beta", []string{"1.2.3-beta"}},
+		{"^1.0.0", nil},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		var a []string
+		for _, v := range c.Exclusions() {
+			a = append(a, v.String())
+		}
+		if !reflect.DeepEqual(a, tc.exclusions) {
+			t.Errorf("Expected %q to exclude %v but got %v", tc.constraint, tc.exclusions, a)
+		}
+	}
+}
+
+func TestConstraintsExactVersion(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		exact      bool
+	}{
+		{"1.2.3", "1.2.3", true},
+		{"=1.2.3", "1.2.3", true},
+		{"v1.2.3-beta.1", "1.2.3-beta.1", true},
+		{"=1.2", "1.2.0", true},
+		{"1.2.3, =1.2.3", "1.2.3", true},
+		{"1.2.3, 1.2.4", "", false},
+		{"1.2.x", "", false},
+		{"=1.x", "", false},
+		{"1", "", false},
+		{"*", "", false},
+		{"^1.2.3", "", false},
+		{"~1.2.3", "", false},
+		{">= 1.2.3, <= 1.2.3", "", false},
+		{"1.2.3, != 1.2.4", "", false},
+		{"1.2.3 || 1.3.0", "", false},
+		{"1.2.3 || 1.2.3", "", false},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		v, exact := c.ExactVersion()
+		if exact != tc.exact {
+			t.Errorf("Expected %q to be exact %t but got %t", tc.constraint, tc.exact, exact)
+			continue
+		}
+		if exact && v.String() != tc.version {
+			t.Errorf("Expected %q to pin %s but got %s", tc.constraint, tc.version, v)
+		}
+		if !exact && v != nil {
+			t.Errorf("Expected no version for %q but got %s", tc.constraint, v)
+		}
+	}
+}
+
 func TestRewriteRange(t *testing.T) {
 	tests := []struct {
 		c  string
@@ -256,6 +1368,18 @@ func TestRewriteRange(t *testing.T) {
 		{"2 - 3", ">= 2, <= 3"},
 		{"2 - 3, 2 - 3", ">= 2, <= 3,>= 2, <= 3"},
 		{"2 - 3, 4.0.0 - 5.1", ">= 2, <= 3,>= 4.0.0, <= 5.1"},
+		{"1.2.* - 2.0.*", ">= 1.2.0, < 2.1.0"},
+		{"1.2.x - 2.0.0", ">= 1.2.0, <= 2.0.0"},
+		{"1.2.0 - 2.x", ">= 1.2.0, < 3.0.0"},
+		{"1.X - 2.0", ">= 1.0.0, <= 2.0"},
+		{"1.2.3 - *", ">= 1.2.3"},
+		{"* - 2.0.*", ">= 0.0.0, < 2.1.0"},
+		{"1.2.3-rc.1 - 2.0.0", ">= 1.2.3-rc.1, <= 2.0.0"},
+		{"1.2.3 - 2.0.0-rc.1 || 3.0.0-beta.1 - 3.0.0", ">= 1.2.3, <= 2.0.0-rc.1||>= 3.0.0-beta.1, <= 3.0.0"},
+		{">=1.2.3 -rc.1", ">=1.2.3 -rc.1"},
+		{"1.2.3 -rc.1 - 2.0.0", "1.2.3 -rc.1 - 2.0.0"},
+		{"a1.2.3 - 2.0.0", "a1.2.3 - 2.0.0"},
+		{"2 - 3,4 - 5", ">= 2, <= 3,>= 4, <= 5"},
 	}
 
 	for _, tc := range tests {
@@ -463,3 +1587,91 @@ func TestConstraintsValidate(t *testing.T) {
 		}
 	}
 }
+
+func TestConstraintsValidateGroups(t *testing.T) {
+	c, err := NewConstraint(">= 1.1, < 2 || ^3.1, != 3.1.4")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	v, err := NewVersion("3.1.4")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ok, groups := c.ValidateGroups(v)
+	if ok {
+		t.Fatal("Expected 3.1.4 to fail validation")
+	}
+
+	e := [][]string{
+		{"3.1.4 is greater than or equal to 2"},
+		{"3.1.4 is equal to 3.1.4"},
+	}
+	if len(groups) != len(e) {
+		t.Fatalf("Expected %d groups but got %d", len(e), len(groups))
+	}
+	for i, g := range groups {
+		if len(g) != len(e[i]) {
+			t.Errorf("Expected %d errors in group %d but got %d", len(e[i]), i, len(g))
+			continue
+		}
+		for j, m := range g {
+			if m.Error() != e[i][j] {
+				t.Errorf("Did not get expected message %q: %s", e[i][j], m)
+			}
+		}
+	}
+
+	v, err = NewVersion("3.2.0")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	ok, groups = c.ValidateGroups(v)
+	if !ok || len(groups) != 0 {
+		t.Errorf("Expected 3.2.0 to pass validation but got %v", groups)
+	}
+}
+
+func TestConstraintExactFastPath(t *testing.T) {
+	constraints := []string{"1.2.3", "=1.2.3", "=1.2.3-beta.1", "=1.2.3+build", "1.2"}
+	versions := []string{
+		"1.2.3", "1.2.3+other", "1.2.4", "1.2.3-beta.1", "1.2.3-beta.01",
+		"1.2.3-beta.2", "1.2.0", "1.2.0-beta",
+	}
+
+	for _, cs := range constraints {
+		c, err := parseConstraint(cs)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+		if !c.exact {
+			t.Errorf("Expected %q to be an exact constraint", cs)
+		}
+
+		for _, vs := range versions {
+			v, err := NewVersion(vs)
+			if err != nil {
+				t.Errorf("err: %s", err)
+				continue
+			}
+
+			if a, e := c.check(v), c.function(v, c); a != e {
+				t.Errorf("Constraint %q with %q: fast path gave %t but expected %t", cs, vs, a, e)
+			}
+		}
+	}
+
+	for _, cs := range []string{"1.2.x", "1", "~1.2.3", ">= 1.2.3"} {
+		c, err := parseConstraint(cs)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+		if c.exact {
+			t.Errorf("Expected %q not to be an exact constraint", cs)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package semver
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var gitDescribeRegex = regexp.MustCompile(`^(.+)-([0-9]+)-g([0-9a-fA-F]+)$`)
+
+// ParseGitDescribe parses the output of `git describe --tags`. For example,
+// v1.2.3-14-gabc1234 is 14 commits past the v1.2.3 tag, at commit abc1234.
+// It returns the version of the tag, the number of commits since the tag and
+// the abbreviated hash of the commit. When describing a commit that is
+// tagged, git only prints the tag, which gives 0 commits and an empty hash.
+// The -dirty mark `git describe --dirty` adds for a modified working tree is
+// ignored, so v1.2.3-14-gabc1234-dirty gives the same as v1.2.3-14-gabc1234.
+func ParseGitDescribe(s string) (base *Version, commitsAhead int, hash string, err error) {
+	s = strings.TrimSuffix(s, "-dirty")
+	tag := s
+	if m := gitDescribeRegex.FindStringSubmatch(s); m != nil {
+		tag, hash = m[1], m[3]
+		commitsAhead, err = strconv.Atoi(m[2])
+		if err != nil {
+			return nil, 0, "", err
+		}
+	}
+
+	base, err = parseVersion(tag, false)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	return base, commitsAhead, hash, nil
+}
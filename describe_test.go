@@ -0,0 +1,43 @@
+package semver
+
+import "testing"
+
+func TestParseGitDescribe(t *testing.T) {
+	tests := []struct {
+		describe string
+		base     string
+		commits  int
+		hash     string
+		err      bool
+	}{
+		{"v1.2.3-14-gabc1234", "1.2.3", 14, "abc1234", false},
+		{"1.2.3-1-g0123456789ab", "1.2.3", 1, "0123456789ab", false},
+		{"v1.2.3", "1.2.3", 0, "", false},
+		{"v1.2.3-rc.1", "1.2.3-rc.1", 0, "", false},
+		{"v1.2.3-rc.1-3-gdeadbee", "1.2.3-rc.1", 3, "deadbee", false},
+		{"v2.0.0-beta-7-gabcdef0", "2.0.0-beta", 7, "abcdef0", false},
+		{"v1.2.3-14-gabc1234-dirty", "1.2.3", 14, "abc1234", false},
+		{"v1.2.3-dirty", "1.2.3", 0, "", false},
+		{"release-14-gabc1234", "", 0, "", true},
+		{"", "", 0, "", true},
+	}
+
+	for _, tc := range tests {
+		base, commits, hash, err := ParseGitDescribe(tc.describe)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected error for %q", tc.describe)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %s", tc.describe, err)
+			continue
+		}
+
+		if base.String() != tc.base || commits != tc.commits || hash != tc.hash {
+			t.Errorf("Expected %q to give %s, %d, %q but got %s, %d, %q",
+				tc.describe, tc.base, tc.commits, tc.hash, base, commits, hash)
+		}
+	}
+}
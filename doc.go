@@ -66,6 +66,7 @@ The basic comparisons are:
 
     * `=`: equal (aliased to no operator)
     * `!=`: not equal
+    * `!`: not equal (aliased to `!=`)
     * `>`: greater than
     * `<`: less than
     * `>=`: greater than or equal to
@@ -78,6 +79,7 @@ These look like:
 
     * `1.2 - 1.4.5` which is equivalent to `>= 1.2, <= 1.4.5`
     * `2.3.4 - 4.5` which is equivalent to `>= 2.3.4, <= 4.5`
+    * `1.2.* - 2.0.*` which is equivalent to `>= 1.2.0, < 2.1.0`
 
 Wildcards In Comparisons
 
@@ -87,8 +89,11 @@ back to the pack level comparison (see tilde below). For example,
 
     * `1.2.x` is equivalent to `>= 1.2.0, < 1.3.0`
     * `>= 1.2.x` is equivalent to `>= 1.2.0`
-    * `<= 2.x` is equivalent to `<= 3`
+    * `<= 2.x` is equivalent to `< 3.0.0`
+    * `<= 1.4.x` and `< 1.4.x` are both equivalent to `< 1.5.0`, the wildcard
+      admitting the whole 1.4 line
     * `*` is equivalent to `>= 0.0.0`
+    * `latest` and an empty constraint are aliases of `*`
 
 Tilde Range Comparisons (Patch)
 
@@ -102,6 +107,13 @@ For example,
     * `~1.2.x` is equivalent to `>= 1.2.0, < 1.3.0`
     * `~1.x` is equivalent to `>= 1, < 2`
 
+The compatible release (`~=`) operator of PEP 440 is also supported. It drops
+the last segment of the version to find the upper bound and needs at least a
+major and a minor version. For example,
+
+    * `~=1.2` is equivalent to `>= 1.2, < 2`
+    * `~=1.2.3` is equivalent to `>= 1.2.3, < 1.3.0`
+
 Caret Range Comparisons (Major)
 
 The caret (`^`) comparison operator is for major level changes. This is useful
@@ -111,5 +123,13 @@ when comparisons of API versions as a major change is API breaking. For example,
     * `^1.2.x` is equivalent to `>= 1.2.0, < 2.0.0`
     * `^2.3` is equivalent to `>= 2.3, < 3`
     * `^2.x` is equivalent to `>= 2.0.0, < 3`
+
+Below 1.0.0 the first non zero version given is treated as the major version,
+as that is where breaking changes are made:
+
+    * `^0.2.3` is equivalent to `>= 0.2.3, < 0.3.0`
+    * `^0.0.3` is equivalent to `>= 0.0.3, < 0.0.4`
+    * `^0.0` is equivalent to `>= 0.0.0, < 0.1.0`
+    * `^0.x` is equivalent to `>= 0.0.0, < 1.0.0`
 */
 package semver
@@ -0,0 +1,22 @@
+package semver
+
+import "strings"
+
+var dockerTagReplacer = strings.NewReplacer("+", "_", ":", "_")
+
+// DockerTag returns the version as a Docker image tag. Tags can not hold a +
+// so the metadata is introduced by an underscore instead: 1.2.3-rc.1+build.5
+// gives 1.2.3-rc.1_build.5. The colon after an epoch is not allowed either
+// and is replaced by an underscore too. See DockerTagWithoutMetadata to leave
+// the metadata out.
+func (v *Version) DockerTag() string {
+	return dockerTagReplacer.Replace(v.String())
+}
+
+// DockerTagWithoutMetadata returns the version as a Docker image tag like
+// DockerTag does, without the metadata: 1.2.3+build.5 gives 1.2.3.
+func (v *Version) DockerTagWithoutMetadata() string {
+	w := *v
+	w.metadata = ""
+	return w.DockerTag()
+}
@@ -0,0 +1,52 @@
+package semver
+
+import (
+	"regexp"
+	"testing"
+)
+
+// dockerTagRegex is the grammar of Docker image tags.
+var dockerTagRegex = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.-]{0,127}$`)
+
+func TestDockerTag(t *testing.T) {
+	tests := []struct {
+		version     string
+		tag         string
+		withoutMeta string
+	}{
+		{"1.2.3", "1.2.3", "1.2.3"},
+		{"v1.2", "1.2.0", "1.2.0"},
+		{"1.2.3-rc.1", "1.2.3-rc.1", "1.2.3-rc.1"},
+		{"1.2.3+build.5", "1.2.3_build.5", "1.2.3"},
+		{"1.2.3-rc.1+build.5-x", "1.2.3-rc.1_build.5-x", "1.2.3-rc.1"},
+	}
+
+	for _, tc := range tests {
+		v := MustParse(tc.version)
+		meta := v.Metadata()
+
+		if a := v.DockerTag(); a != tc.tag {
+			t.Errorf("Expected the Docker tag of %s to be %s but got %s", tc.version, tc.tag, a)
+		}
+		if a := v.DockerTagWithoutMetadata(); a != tc.withoutMeta {
+			t.Errorf("Expected the Docker tag without metadata of %s to be %s but got %s", tc.version, tc.withoutMeta, a)
+		}
+		if !dockerTagRegex.MatchString(v.DockerTag()) {
+			t.Errorf("Expected %s to be a valid Docker tag", v.DockerTag())
+		}
+		if tag, err := NewVersion(v.DockerTagWithoutMetadata()); err != nil || tag.Metadata() != "" {
+			t.Errorf("Expected the Docker tag without metadata of %s to have no metadata", tc.version)
+		}
+		if v.Metadata() != meta {
+			t.Errorf("Expected the metadata of %s to be left unchanged but got %s", tc.version, v.Metadata())
+		}
+	}
+
+	v, err := NewVersionEpoch("2:1.2.3+build")
+	if err != nil {
+		t.Fatalf("Error parsing version: %s", err)
+	}
+	if a := v.DockerTag(); a != "2_1.2.3_build" || !dockerTagRegex.MatchString(a) {
+		t.Errorf("Expected the Docker tag of %s to be 2_1.2.3_build but got %s", v, a)
+	}
+}
@@ -0,0 +1,84 @@
+package semver
+
+import (
+	"regexp"
+	"strings"
+)
+
+var extractCoreRegex = regexp.MustCompile(`[0-9]+\.[0-9]+\.[0-9]+`)
+
+// fileExtensions are the file extensions left out of the pre-release of an
+// extracted version, so mylib-1.2.3-rc.1.tar.gz gives 1.2.3-rc.1.
+var fileExtensions = map[string]bool{
+	"tar": true, "gz": true, "tgz": true, "bz2": true, "xz": true, "zst": true,
+	"zip": true, "7z": true, "jar": true, "war": true, "whl": true, "gem": true,
+	"deb": true, "rpm": true, "apk": true, "dmg": true, "pkg": true, "msi": true,
+	"exe": true, "so": true, "dll": true, "asc": true, "sig": true, "sha256": true,
+	"md5": true, "json": true, "txt": true,
+}
+
+// ExtractVersion returns the first version embedded in a string such as a
+// file name or a URL, like 1.2.3 in https://cdn/pkg/mylib-1.2.3.tar.gz. The
+// version needs a major, minor and patch version and may have a pre-release
+// introduced by a -. Numbers with more than three segments, such as the
+// address 10.0.0.1, and numbers glued to letters, as in python3.11.2, are
+// skipped. File extensions ending the pre-release are left out, but any other
+// hyphenated suffix is read as a pre-release: mylib-1.2.3-linux.tar.gz gives
+// 1.2.3-linux. It returns false when no version is found.
+func ExtractVersion(s string) (*Version, bool) {
+	for _, m := range extractCoreRegex.FindAllStringIndex(s, -1) {
+		start, end := m[0], m[1]
+		if !versionStart(s, start) {
+			continue
+		}
+		if end < len(s) && (isAlphanumeric(s[end]) ||
+			(s[end] == '.' && end+1 < len(s) && s[end+1] >= '0' && s[end+1] <= '9')) {
+			continue
+		}
+
+		core := s[start:end]
+		if pre := extractPrerelease(s[end:]); pre != "" {
+			if v, err := parseVersion(core+"-"+pre, false); err == nil {
+				return v, true
+			}
+		}
+		if v, err := parseVersion(core, false); err == nil {
+			return v, true
+		}
+	}
+
+	return nil, false
+}
+
+// extractPrerelease returns the pre-release starting s, without the - before
+// it and without the file extensions after it.
+func extractPrerelease(s string) string {
+	if s == "" || s[0] != '-' {
+		return ""
+	}
+
+	end := 1
+	for end < len(s) && (isAlphanumeric(s[end]) || s[end] == '-' || s[end] == '.') {
+		end++
+	}
+
+	ids := strings.Split(strings.Trim(s[1:end], "."), ".")
+	for len(ids) > 0 && fileExtensions[strings.ToLower(ids[len(ids)-1])] {
+		ids = ids[:len(ids)-1]
+	}
+
+	return strings.Join(ids, ".")
+}
+
+// versionStart tells if a version can start at s[i]: after a separator or a
+// v that is not part of a word, as in v1.2.3 but not in python3.11.2.
+func versionStart(s string, i int) bool {
+	if i > 0 && (s[i-1] == 'v' || s[i-1] == 'V') {
+		i--
+	}
+	return i == 0 || (s[i-1] != '.' && !isAlphanumeric(s[i-1]))
+}
+
+func isAlphanumeric(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,42 @@
+package semver
+
+import "testing"
+
+func TestExtractVersion(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		found    bool
+	}{
+		{"https://cdn/pkg/mylib-1.2.3.tar.gz", "1.2.3", true},
+		{"mylib-1.2.3-rc.1.tar.gz", "1.2.3-rc.1", true},
+		{"mylib-v2.0.0-beta.zip", "2.0.0-beta", true},
+		{"mylib_1.2.3_linux_amd64.tar.gz", "1.2.3", true},
+		{"/releases/download/v0.14.1/tool-0.14.1.deb", "0.14.1", true},
+		{"https://10.0.0.1/pkg/mylib-3.4.5.jar", "3.4.5", true},
+		{"build-20231014.1.2.3.4-mylib-1.0.0.whl", "1.0.0", true},
+		{"python3.11.2-mylib-1.2.3.tgz", "1.2.3", true},
+		{"mylib-1.2.3-linux.tar.gz", "1.2.3-linux", true},
+		{"mylib-1.2.3-", "1.2.3", true},
+		{"mylib-1.2.3-.tar.gz", "1.2.3", true},
+		{"mylib-1.2.3", "1.2.3", true},
+		{"mylib-1.2.tar.gz", "", false},
+		{"10.0.0.1", "", false},
+		{"mylib-1.2.3a.zip", "", false},
+		{"", "", false},
+	}
+
+	for _, tc := range tests {
+		v, found := ExtractVersion(tc.input)
+		if found != tc.found {
+			t.Errorf("Expected %q to find a version %t but got %t", tc.input, tc.found, found)
+			continue
+		}
+		if found && v.String() != tc.expected {
+			t.Errorf("Expected %q to give %s but got %s", tc.input, tc.expected, v)
+		}
+		if !found && v != nil {
+			t.Errorf("Expected no version for %q but got %s", tc.input, v)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+var goVersionRegex = regexp.MustCompile(`^go([0-9]+)(\.[0-9]+)?(\.[0-9]+)?((?:rc|beta)[0-9]+)?(?:\s.*)?$`)
+
+// SatisfiesGoVersion tells if the version of the Go runtime, as given by
+// runtime.Version(), satisfies the constraint. The Go version is converted to
+// a semantic version first: go1.21.4 gives 1.21.4, go1.21 gives 1.21.0 and
+// go1.21rc2 gives the pre-release 1.21.0-rc2. An error is returned when the
+// constraint can not be parsed or the runtime is not a Go release, such as a
+// development build.
+func SatisfiesGoVersion(constraint string) (bool, error) {
+	return satisfiesGoVersion(runtime.Version(), constraint)
+}
+
+func satisfiesGoVersion(goVersion, constraint string) (bool, error) {
+	v, err := parseGoVersion(goVersion)
+	if err != nil {
+		return false, err
+	}
+
+	c, err := NewConstraint(constraint)
+	if err != nil {
+		return false, err
+	}
+
+	return c.Check(v), nil
+}
+
+// parseGoVersion converts a Go version, such as go1.21.4, to a semantic
+// version.
+func parseGoVersion(s string) (*Version, error) {
+	m := goVersionRegex.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("not a Go release version: %s", s)
+	}
+
+	ver := m[1] + m[2] + m[3]
+	if m[4] != "" {
+		if m[3] == "" {
+			ver += ".0"
+		}
+		ver += "-" + m[4]
+	}
+
+	return parseVersion(ver, false)
+}
+
+// ParseGoListVersions parses the versions found on a line printed by
+// `go list -m`, such as `golang.org/x/text v0.3.0 v0.3.1 v0.3.2` given by the
+// -versions flag or `example.com/a v1.2.3 => example.com/b v1.2.4` for a
+// replaced module. Go module versions always start with a v so the module
+// paths, the replacement arrow and replacement directories are skipped. The
+// versions are returned in the order of the line, the version of a
+// replacement coming after the version it replaces.
+func ParseGoListVersions(line string) ([]*Version, error) {
+	var vs []*Version
+	for _, f := range strings.Fields(line) {
+		if len(f) < 2 || f[0] != 'v' || f[1] < '0' || f[1] > '9' {
+			continue
+		}
+
+		v, err := parseVersion(f, false)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", f, err)
+		}
+		vs = append(vs, v)
+	}
+
+	return vs, nil
+}
@@ -0,0 +1,106 @@
+package semver
+
+import "testing"
+
+func TestParseGoVersion(t *testing.T) {
+	tests := []struct {
+		goVersion string
+		version   string
+		err       bool
+	}{
+		{"go1.21.4", "1.21.4", false},
+		{"go1.21", "1.21.0", false},
+		{"go1.9", "1.9.0", false},
+		{"go1.21rc2", "1.21.0-rc2", false},
+		{"go1.8beta1", "1.8.0-beta1", false},
+		{"go1.22.0 X:boringcrypto", "1.22.0", false},
+		{"devel +a1b2c3d Tue Jan 1 00:00:00 2019 +0000", "", true},
+		{"1.21.4", "", true},
+	}
+
+	for _, tc := range tests {
+		v, err := parseGoVersion(tc.goVersion)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected error for %q", tc.goVersion)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %s", tc.goVersion, err)
+			continue
+		}
+		if v.String() != tc.version {
+			t.Errorf("Expected %q to give %s but got %s", tc.goVersion, tc.version, v)
+		}
+	}
+}
+
+func TestSatisfiesGoVersion(t *testing.T) {
+	tests := []struct {
+		goVersion  string
+		constraint string
+		check      bool
+	}{
+		{"go1.21.4", ">= 1.21", true},
+		{"go1.21", "~1.21.0", true},
+		{"go1.20.12", ">= 1.21", false},
+		{"go1.21rc2", ">= 1.21", false},
+		{"go1.21rc2", ">= 1.21.0-rc1", true},
+	}
+
+	for _, tc := range tests {
+		a, err := satisfiesGoVersion(tc.goVersion, tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+		if a != tc.check {
+			t.Errorf("Constraint %q reports %t for %q but should be %t", tc.constraint, a, tc.goVersion, tc.check)
+		}
+	}
+
+	if _, err := satisfiesGoVersion("go1.21.4", ">= bar"); err == nil {
+		t.Error("Expected error for a bad constraint")
+	}
+}
+
+func TestParseGoListVersions(t *testing.T) {
+	tests := []struct {
+		line     string
+		versions []string
+		err      bool
+	}{
+		{"golang.org/x/text v0.1.0 v0.2.0 v0.3.0 v0.3.1-0.20180807135948-17ff2d5776d2 v0.3.2", []string{"v0.1.0", "v0.2.0", "v0.3.0", "v0.3.1-0.20180807135948-17ff2d5776d2", "v0.3.2"}, false},
+		{"github.com/docker/docker v17.12.0-ce-rc1+incompatible", []string{"v17.12.0-ce-rc1+incompatible"}, false},
+		{"example.com/a v1.2.3 => example.com/b v1.2.4", []string{"v1.2.3", "v1.2.4"}, false},
+		{"example.com/a v1.2.3 => ../a", []string{"v1.2.3"}, false},
+		{"v1.0.0\tv1.1.0\n", []string{"v1.0.0", "v1.1.0"}, false},
+		{"example.com/a", nil, false},
+		{"example.com/a v1.2.3.4", nil, true},
+	}
+
+	for _, tc := range tests {
+		vs, err := ParseGoListVersions(tc.line)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected error for %q", tc.line)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %s", tc.line, err)
+			continue
+		}
+
+		if len(vs) != len(tc.versions) {
+			t.Errorf("Expected %d versions from %q but got %d", len(tc.versions), tc.line, len(vs))
+			continue
+		}
+		for i, v := range vs {
+			if v.Original() != tc.versions[i] {
+				t.Errorf("Expected version %d of %q to be %s but got %s", i, tc.line, tc.versions[i], v.Original())
+			}
+		}
+	}
+}
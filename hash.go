@@ -0,0 +1,43 @@
+package semver
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+)
+
+// Hash returns a hash of the version, for use in hash based structures. It
+// follows Equal: equal versions hash the same, so versions only differing by
+// their metadata, such as 1.2.3+build.1 and 1.2.3+build.2, share a hash. The
+// ranks set with SetPrereleaseRank are not taken into account. The hash is
+// stable across runs and platforms.
+func (v *Version) Hash() uint64 {
+	return v.hash(false)
+}
+
+// HashStrict returns a hash of the version like Hash does, the metadata
+// included. Versions only differing by their metadata get different hashes.
+func (v *Version) HashStrict() uint64 {
+	return v.hash(true)
+}
+
+func (v *Version) hash(metadata bool) uint64 {
+	h := fnv.New64a()
+
+	var b [8]byte
+	for _, n := range [4]int64{v.epoch, v.major, v.minor, v.patch} {
+		binary.BigEndian.PutUint64(b[:], uint64(n))
+		h.Write(b[:])
+	}
+
+	if v.pre != "" {
+		h.Write([]byte{'-'})
+		h.Write([]byte(v.pre))
+	}
+
+	if metadata && v.metadata != "" {
+		h.Write([]byte{'+'})
+		h.Write([]byte(v.metadata))
+	}
+
+	return h.Sum64()
+}
@@ -0,0 +1,41 @@
+package semver
+
+import "testing"
+
+func TestHash(t *testing.T) {
+	tests := []struct {
+		v1, v2 string
+		same   bool
+		strict bool
+	}{
+		{"1.2.3", "1.2.3", true, true},
+		{"1.2.3", "v1.2", false, false},
+		{"1.2", "v1.2.0", true, true},
+		{"1.2.3+build.1", "1.2.3+build.2", true, false},
+		{"1.2.3+build.1", "1.2.3", true, false},
+		{"1.2.3-beta.1", "1.2.3-beta.01", false, false},
+		{"1.2.3-beta.1", "1.2.3-beta.2", false, false},
+		{"1.2.3-beta", "1.2.3", false, false},
+		{"1.2.3-beta.1", "1.2.3-beta1", false, false},
+		{"1.2.3", "1.23", false, false},
+	}
+
+	for _, tc := range tests {
+		v1, v2 := MustParse(tc.v1), MustParse(tc.v2)
+
+		if a := v1.Hash() == v2.Hash(); a != tc.same {
+			t.Errorf("Expected %s and %s to share a hash to be %t", tc.v1, tc.v2, tc.same)
+		}
+		if a := v1.HashStrict() == v2.HashStrict(); a != tc.strict {
+			t.Errorf("Expected %s and %s to share a strict hash to be %t", tc.v1, tc.v2, tc.strict)
+		}
+		if tc.same != v1.Equal(v2) {
+			t.Errorf("Expected the hashes of %s and %s to agree with Equal", tc.v1, tc.v2)
+		}
+	}
+
+	e, _ := NewVersionEpoch("1:1.2.3")
+	if e.Hash() == MustParse("1.2.3").Hash() {
+		t.Error("Expected the epoch to change the hash")
+	}
+}
@@ -0,0 +1,50 @@
+package semver
+
+import "sync"
+
+// Interner shares the instances of equal versions. Holding interned versions
+// saves memory when the same versions are loaded many times. The zero value
+// is ready to use and an Interner is safe to use from several goroutines.
+// The versions are held as long as the Interner is, or until Reset is called.
+type Interner struct {
+	mu       sync.RWMutex
+	versions map[string]*Version
+}
+
+// Intern returns a shared instance of the version. Versions with the same
+// String() value (e.g., 1.2.3 and v1.2.3) give the same instance, which is
+// the first one that was interned. So, the Original() value of the returned
+// version may differ from the one passed in. The methods producing a new
+// version return a copy, so sharing an interned version is safe. Decoding
+// into an interned version with UnmarshalJSON or UnmarshalBinary changes it
+// for all of its holders and must not be done.
+func (in *Interner) Intern(v *Version) *Version {
+	k := v.String()
+
+	in.mu.RLock()
+	i, ok := in.versions[k]
+	in.mu.RUnlock()
+	if ok {
+		return i
+	}
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if i, ok := in.versions[k]; ok {
+		return i
+	}
+	if in.versions == nil {
+		in.versions = make(map[string]*Version)
+	}
+	in.versions[k] = v
+
+	return v
+}
+
+// Reset drops the interned versions. The versions already returned are left
+// as they are.
+func (in *Interner) Reset() {
+	in.mu.Lock()
+	in.versions = nil
+	in.mu.Unlock()
+}
@@ -0,0 +1,44 @@
+package semver
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestIntern(t *testing.T) {
+	var in Interner
+	v1 := in.Intern(MustParse("1.2.3-beta+build.1"))
+	v2 := in.Intern(MustParse("v1.2.3-beta+build.1"))
+	if v1 != v2 {
+		t.Error("Expected equal versions to be interned to the same instance")
+	}
+	if v2.Original() != "1.2.3-beta+build.1" {
+		t.Errorf("Expected the first interned version but got %q", v2.Original())
+	}
+
+	if in.Intern(MustParse("1.2.3-beta")) == v1 {
+		t.Error("Expected versions with a different metadata to be distinct")
+	}
+
+	var wg sync.WaitGroup
+	res := make([]*Version, 50)
+	for i := range res {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res[i] = in.Intern(MustParse("4.5.6"))
+		}(i)
+	}
+	wg.Wait()
+
+	for _, v := range res {
+		if v != res[0] {
+			t.Fatal("Expected concurrent calls to return the same instance")
+		}
+	}
+
+	in.Reset()
+	if v := MustParse("v1.2.3-beta+build.1"); in.Intern(v) != v {
+		t.Error("Expected a reset Interner to hold no version")
+	}
+}
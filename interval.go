@@ -0,0 +1,97 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NewIntervalConstraint returns a Constraints instance from the interval
+// notation used by Maven and NuGet. A `[` or `]` marks an inclusive bound
+// while a `(` or `)` marks an exclusive one. For example, `[1.2.0,2.0.0)` is
+// equivalent to `>= 1.2.0, < 2.0.0`. A side left empty is unbounded so
+// `[1.0.0,)` is equivalent to `>= 1.0.0`, and `[1.2.3]` matches exactly
+// 1.2.3. Multiple intervals separated by commas are OR'ed together.
+func NewIntervalConstraint(c string) (*Constraints, error) {
+	var or [][]*constraint
+
+	rest := strings.TrimSpace(c)
+	for rest != "" {
+		if len(or) > 0 {
+			if rest[0] != ',' {
+				return nil, fmt.Errorf("improper interval: %s", c)
+			}
+			rest = strings.TrimSpace(rest[1:])
+		}
+
+		end := strings.IndexAny(rest, "])")
+		if end < 0 || (rest[0] != '[' && rest[0] != '(') {
+			return nil, fmt.Errorf("improper interval: %s", c)
+		}
+
+		group, err := parseInterval(rest[:end+1])
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, group)
+
+		rest = strings.TrimSpace(rest[end+1:])
+	}
+
+	if len(or) == 0 {
+		return nil, fmt.Errorf("improper interval: %s", c)
+	}
+
+	o := &Constraints{constraints: or, exacts: exactSet(or)}
+	return o, nil
+}
+
+// parseInterval parses a single interval, brackets included, into an AND
+// group of constraints.
+func parseInterval(i string) ([]*constraint, error) {
+	lowIncl := i[0] == '['
+	highIncl := i[len(i)-1] == ']'
+	body := i[1 : len(i)-1]
+
+	bounds := strings.Split(body, ",")
+	var ops []string
+	switch len(bounds) {
+	case 1:
+		// A single version is only meaningful as an exact match.
+		if !lowIncl || !highIncl {
+			return nil, fmt.Errorf("improper interval: %s", i)
+		}
+		ops = []string{"="}
+	case 2:
+		ops = []string{">", "<"}
+		if lowIncl {
+			ops[0] = ">="
+		}
+		if highIncl {
+			ops[1] = "<="
+		}
+	default:
+		return nil, fmt.Errorf("improper interval: %s", i)
+	}
+
+	var result []*constraint
+	for k, b := range bounds {
+		b = strings.TrimSpace(b)
+		if b == "" {
+			continue
+		}
+
+		// The bounds are versions, not constraints: a short bound such as
+		// 2 stands for 2.0.0 rather than the wildcard 2.x.
+		v, err := parseVersion(b, false)
+		if err != nil {
+			return nil, fmt.Errorf("improper interval: %s", i)
+		}
+		result = append(result, newComparison(ops[k], v))
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("improper interval: %s", i)
+	}
+
+	return result, nil
+}
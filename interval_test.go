@@ -0,0 +1,119 @@
+package semver
+
+import "testing"
+
+func TestNewIntervalConstraint(t *testing.T) {
+	tests := []struct {
+		interval string
+		ors      int
+		count    int
+		err      bool
+	}{
+		{"[1.2.0,2.0.0)", 1, 2, false},
+		{"[1.2.0]", 1, 1, false},
+		{"[1.0.0,)", 1, 1, false},
+		{"(,2.0.0]", 1, 1, false},
+		{" [1.0, 2.0) , [3.0,) ", 2, 2, false},
+		{"", 0, 0, true},
+		{"(,)", 0, 0, true},
+		{"(1.2.0)", 0, 0, true},
+		{"[1.0,2.0,3.0]", 0, 0, true},
+		{"[1.0,2.0", 0, 0, true},
+		{"1.0,2.0]", 0, 0, true},
+		{"[1.0,2.0] [3.0,4.0]", 0, 0, true},
+		{"[foo,2.0]", 0, 0, true},
+	}
+
+	for _, tc := range tests {
+		c, err := NewIntervalConstraint(tc.interval)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected error for %q didn't occur", tc.interval)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unexpected error for %q: %s", tc.interval, err)
+			continue
+		}
+
+		if l := len(c.constraints); l != tc.ors {
+			t.Errorf("Expected %q to have %d ORs but got %d", tc.interval, tc.ors, l)
+		}
+		if l := len(c.constraints[0]); l != tc.count {
+			t.Errorf("Expected %q to have %d constraints but got %d", tc.interval, tc.count, l)
+		}
+	}
+}
+
+func TestIntervalConstraintCheck(t *testing.T) {
+	tests := []struct {
+		interval string
+		version  string
+		check    bool
+	}{
+		{"[1.2.0,2.0.0]", "1.2.0", true},
+		{"[1.2.0,2.0.0]", "2.0.0", true},
+		{"[1.2.0,2.0.0]", "2.0.1", false},
+		{"[1.2.0,2.0.0)", "1.2.0", true},
+		{"[1.2.0,2.0.0)", "1.9.9", true},
+		{"[1.2.0,2.0.0)", "2.0.0", false},
+		{"(1.2.0,2.0.0]", "1.2.0", false},
+		{"(1.2.0,2.0.0]", "1.2.1", true},
+		{"(1.2.0,2.0.0]", "2.0.0", true},
+		{"(1.2.0,2.0.0)", "1.2.0", false},
+		{"(1.2.0,2.0.0)", "1.5.0", true},
+		{"(1.2.0,2.0.0)", "2.0.0", false},
+		{"[1.0.0,)", "1.0.0", true},
+		{"[1.0.0,)", "99.0.0", true},
+		{"[1.0.0,)", "0.9.0", false},
+		{"(1.0.0,)", "1.0.0", false},
+		{"(,2.0.0]", "0.0.1", true},
+		{"(,2.0.0]", "2.0.0", true},
+		{"(,2.0.0)", "2.0.0", false},
+		{"[1.2.3]", "1.2.3", true},
+		{"[1.2.3]", "1.2.4", false},
+		{"[1.0,2.0),[3.0,)", "2.5.0", false},
+		{"[1,2)", "1.9.9", true},
+		{"[1,2)", "2.0.0", false},
+		{"[1,2)", "2.5.0", false},
+		{"(,2)", "1.0.0", true},
+		{"(,2)", "2.9.0", false},
+		{"[1.5,2]", "1.5.0", true},
+		{"[1.5,2]", "2.0.0", true},
+		{"[1.5,2]", "2.1.0", false},
+		{"[2]", "2.0.0", true},
+		{"[2]", "2.1.0", false},
+		{"[1.0,2.0),[3.0,)", "3.1.0", true},
+	}
+
+	for _, tc := range tests {
+		c, err := NewIntervalConstraint(tc.interval)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		v, err := NewVersion(tc.version)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		a := c.Check(v)
+		if a != tc.check {
+			t.Errorf("Interval '%s' failing with '%s'", tc.interval, tc.version)
+		}
+	}
+
+	c, err := NewIntervalConstraint("[1.2.3]")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if v, ok := c.ExactVersion(); !ok || v.String() != "1.2.3" {
+		t.Errorf("Expected [1.2.3] to pin 1.2.3 but got %v", v)
+	}
+	if a := c.Kind(); a != KindExact {
+		t.Errorf("Expected [1.2.3] to be of kind exact but got %s", a)
+	}
+}
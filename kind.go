@@ -0,0 +1,111 @@
+package semver
+
+import "fmt"
+
+// ConstraintKind describes the form of constraints, as reported by Kind.
+type ConstraintKind int
+
+const (
+	// KindAny is for constraints matching any version (e.g., *, x or latest).
+	KindAny ConstraintKind = iota
+
+	// KindExact is for constraints pinning a single version (e.g., 1.2.3 or
+	// =1.2.3).
+	KindExact
+
+	// KindCaret is for a caret constraint (e.g., ^1.2.3).
+	KindCaret
+
+	// KindTilde is for a tilde constraint (e.g., ~1.2.3 or ~=1.2).
+	KindTilde
+
+	// KindWildcard is for a version with a wildcard or a left out minor
+	// version (e.g., 1.2.x or 1).
+	KindWildcard
+
+	// KindRange is for comparisons and their combinations (e.g., >= 1.2.0,
+	// >= 1.2.0, < 2.0.0 or 1.2 - 1.4.5).
+	KindRange
+
+	// KindUnion is for constraints with several OR groups (e.g., ^1.2.0 ||
+	// ^2.0.0).
+	KindUnion
+
+	// KindBranch is for a branch pin allowed with the AllowBranchTokens
+	// option (e.g., main).
+	KindBranch
+)
+
+// String returns the name of the constraint kind.
+func (k ConstraintKind) String() string {
+	switch k {
+	case KindAny:
+		return "any"
+	case KindExact:
+		return "exact"
+	case KindCaret:
+		return "caret"
+	case KindTilde:
+		return "tilde"
+	case KindWildcard:
+		return "wildcard"
+	case KindRange:
+		return "range"
+	case KindUnion:
+		return "union"
+	case KindBranch:
+		return "branch"
+	default:
+		return fmt.Sprintf("ConstraintKind(%d)", int(k))
+	}
+}
+
+// Kind classifies the constraints by their parsed form. Several OR groups give
+// KindUnion whatever the groups are. A single group made of one constraint
+// gives the kind of its operator, other groups give KindRange unless they pin
+// one version, as ExactVersion tells. A branch pin gives KindBranch and
+// NoneConstraint, which has no group, gives KindRange.
+func (cs Constraints) Kind() ConstraintKind {
+	if cs.branch != "" {
+		return KindBranch
+	}
+	if len(cs.constraints) > 1 {
+		return KindUnion
+	}
+	if cs.IsAny() {
+		return KindAny
+	}
+	if _, ok := cs.ExactVersion(); ok {
+		return KindExact
+	}
+	if len(cs.constraints) == 0 {
+		return KindRange
+	}
+
+	// Constraints matching any version, as in `*, ^1.2.0`, do not change
+	// the kind of the others.
+	var group []*constraint
+	for _, c := range cs.constraints[0] {
+		if !c.matchAll {
+			group = append(group, c)
+		}
+	}
+	if len(group) != 1 {
+		return KindRange
+	}
+
+	switch c := group[0]; c.op {
+	case "=":
+		// A wildcard major version, as in x, allows any version.
+		if c.dirty && !c.minorDirty && !c.patchDirty {
+			return KindAny
+		}
+		return KindWildcard
+	case "^":
+		return KindCaret
+	case "~", "~=":
+		return KindTilde
+	default:
+		return KindRange
+	}
+}
@@ -0,0 +1,82 @@
+package semver
+
+import "testing"
+
+func TestConstraintsKind(t *testing.T) {
+	tests := []struct {
+		constraint string
+		kind       ConstraintKind
+	}{
+		{"*", KindAny},
+		{"latest", KindAny},
+		{"", KindAny},
+		{"x", KindAny},
+		{"1.2.3", KindExact},
+		{"=1.2.3", KindExact},
+		{"v1.2.3-beta.1", KindExact},
+		{"1.2.3, =1.2.3", KindExact},
+		{"^1.2.3", KindCaret},
+		{"^1.x", KindCaret},
+		{"*, ^1.2.3", KindCaret},
+		{"~1.2.3", KindTilde},
+		{"~>1.2", KindTilde},
+		{"~=1.2", KindTilde},
+		{"1.2.x", KindWildcard},
+		{"1.2", KindExact},
+		{"1", KindWildcard},
+		{">= 1.2.0", KindRange},
+		{"!= 1.2.0", KindRange},
+		{"< 1.x", KindRange},
+		{">= 1.2.0, < 2.0.0", KindRange},
+		{"^1.2.3, != 1.4.0", KindRange},
+		{"1.2 - 1.4.5", KindRange},
+		{"1.2.3, 1.2.4", KindRange},
+		{"^1.2.0 || ^2.0.0", KindUnion},
+		{"1.2.3 || 1.2.3", KindUnion},
+		{"* || 1.2.3", KindUnion},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		if a := c.Kind(); a != tc.kind {
+			t.Errorf("Expected %q to be of kind %s but got %s", tc.constraint, tc.kind, a)
+		}
+	}
+
+	if a := NoneConstraint().Kind(); a != KindRange {
+		t.Errorf("Expected NoneConstraint to be of kind range but got %s", a)
+	}
+
+	c, _ := NewConstraintWithOptions("main", ConstraintOptions{AllowBranchTokens: []string{"main"}})
+	if a := c.Kind(); a != KindBranch {
+		t.Errorf("Expected a branch pin to be of kind branch but got %s", a)
+	}
+}
+
+func TestConstraintKindString(t *testing.T) {
+	tests := []struct {
+		kind     ConstraintKind
+		expected string
+	}{
+		{KindAny, "any"},
+		{KindExact, "exact"},
+		{KindCaret, "caret"},
+		{KindTilde, "tilde"},
+		{KindWildcard, "wildcard"},
+		{KindRange, "range"},
+		{KindUnion, "union"},
+		{KindBranch, "branch"},
+		{ConstraintKind(42), "ConstraintKind(42)"},
+	}
+
+	for _, tc := range tests {
+		if a := tc.kind.String(); a != tc.expected {
+			t.Errorf("Expected %d to be %q but got %q", int(tc.kind), tc.expected, a)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package semver
+
+import (
+	"sort"
+	"strings"
+)
+
+// ToNodeSemver renders the constraints as a range string accepted by the
+// node-semver package. Comparators are separated by spaces and OR groups by
+// `||`. Since the meaning of some operators differ between the two packages
+// (e.g., `^0.2.3` or `<1.x`), every comparator is written as the explicit
+// range it stands for. For example, `^1.2.3` gives `>=1.2.3 <2.0.0`.
+// node-semver has no `!=` operator so the exclusions split the OR group into
+// the ranges between the excluded versions, leaving out the ones the group
+// does not reach. Constraints matching no version, such as NoneConstraint or
+// a branch, give `<0.0.0-0` as node-semver reads an empty range as `*`.
+func (cs Constraints) ToNodeSemver() string {
+	var ors []string
+	for _, o := range cs.constraints {
+		var base []string
+		var excluded []versionRange
+		var r versionRange
+		for _, c := range o {
+			if c.op == "!=" {
+				excluded = append(excluded, c.exclusionRange())
+				continue
+			}
+			base = append(base, c.nodeComparators()...)
+			r = r.intersect(c.bounds())
+		}
+
+		for _, gap := range nodeGaps(excluded) {
+			if len(excluded) > 0 && !r.intersects(gap) {
+				continue
+			}
+
+			a := append(base[:len(base):len(base)], gap.within(r).nodeComparators()...)
+			if len(a) == 0 {
+				ors = append(ors, "*")
+				continue
+			}
+			ors = append(ors, strings.Join(a, " "))
+		}
+	}
+
+	if len(ors) == 0 {
+		return "<0.0.0-0"
+	}
+
+	return strings.Join(ors, " || ")
+}
+
+// nodeGaps returns the ranges between the excluded ones, in order. Without
+// exclusions it gives a single range admitting any version.
+func nodeGaps(excluded []versionRange) []versionRange {
+	sort.Slice(excluded, func(i, j int) bool {
+		return excluded[i].min.LessThan(excluded[j].min)
+	})
+
+	gaps := make([]versionRange, 0, len(excluded)+1)
+	var gap versionRange
+	for i := 0; i < len(excluded); i++ {
+		e := excluded[i]
+
+		// Merge the exclusions overlapping this one.
+		for i+1 < len(excluded) && e.max != nil && !e.max.LessThan(excluded[i+1].min) {
+			n := excluded[i+1]
+			if n.max == nil || n.max.GreaterThan(e.max) || (n.max.Equal(e.max) && n.maxIncl) {
+				e.max, e.maxIncl = n.max, n.maxIncl
+			}
+			i++
+		}
+
+		gap.max = e.min
+		gaps = append(gaps, gap)
+		if e.max == nil {
+			return gaps
+		}
+		gap = versionRange{min: e.max, minIncl: !e.maxIncl}
+	}
+
+	return append(gaps, gap)
+}
+
+// within returns the range with the bounds o already sets left out, so the
+// comparators of a gap do not repeat the ones of its group.
+func (r versionRange) within(o versionRange) versionRange {
+	if r.min != nil && o.min != nil {
+		if d := r.min.Compare(o.min); d < 0 || (d == 0 && (r.minIncl || !o.minIncl)) {
+			r.min = nil
+		}
+	}
+	if r.max != nil && o.max != nil {
+		if d := r.max.Compare(o.max); d > 0 || (d == 0 && (r.maxIncl || !o.maxIncl)) {
+			r.max = nil
+		}
+	}
+
+	return r
+}
+
+// nodeComparators returns the node-semver comparators matching the range.
+func (r versionRange) nodeComparators() []string {
+	var cmps []string
+	if r.min != nil {
+		op := ">"
+		if r.minIncl {
+			op = ">="
+		}
+		cmps = append(cmps, op+r.min.String())
+	}
+	if r.max != nil {
+		op := "<"
+		if r.maxIncl {
+			op = "<="
+		}
+		cmps = append(cmps, op+r.max.String())
+	}
+
+	return cmps
+}
+
+// nodeComparators returns the node-semver comparators matching the range of
+// a constraint.
+func (c *constraint) nodeComparators() []string {
+	if c.matchAll {
+		return nil
+	}
+	if c.exact {
+		return []string{c.con.String()}
+	}
+
+	return c.bounds().nodeComparators()
+}
@@ -0,0 +1,62 @@
+package semver
+
+import "testing"
+
+func TestToNodeSemver(t *testing.T) {
+	tests := []struct {
+		constraint string
+		node       string
+	}{
+		{">= 1.2.3, < 2.0.0", ">=1.2.3 <2.0.0"},
+		{"=> 1.2.3, =< 2.0.0", ">=1.2.3 <=2.0.0"},
+		{"> 1.2.3", ">1.2.3"},
+		{"1.2.3", "1.2.3"},
+		{"=v1.2.3-beta.1", "1.2.3-beta.1"},
+		{"^1.2.3", ">=1.2.3 <2.0.0"},
+		{"~1.2.3", ">=1.2.3 <1.3.0"},
+		{"~1", ">=1.0.0 <2.0.0"},
+		{"~=1.2", ">=1.2.0 <2.0.0"},
+		{"~=1.2.3", ">=1.2.3 <1.3.0"},
+		{"1.2.x", ">=1.2.0 <1.3.0"},
+		{"<= 1.x", "<2.0.0"},
+		{"< 1.2.x", "<1.3.0"},
+		{"1.0 - 2.0", ">=1.0.0 <=2.0.0"},
+		{"~1.2.3 || 3.0.0", ">=1.2.3 <1.3.0 || 3.0.0"},
+		{"*", "*"},
+		{"latest || ^1.0.0", "* || >=1.0.0 <2.0.0"},
+		{"!= 1.2.3", "<1.2.3 || >1.2.3"},
+		{"^1.0.0, != 1.2.3", ">=1.0.0 <2.0.0 <1.2.3 || >=1.0.0 <2.0.0 >1.2.3"},
+		{"^1.0.0, != 1.2.x", ">=1.0.0 <2.0.0 <1.2.0 || >=1.0.0 <2.0.0 >=1.3.0"},
+		{"!= 1.2.3, != 1.5.0", "<1.2.3 || >1.2.3 <1.5.0 || >1.5.0"},
+		{"!= 1.5.0, != 1.2.3", "<1.2.3 || >1.2.3 <1.5.0 || >1.5.0"},
+		{"!= 1.2.x, != 1.2.5", "<1.2.0 || >=1.3.0"},
+		{"^1.0.0, != 0.5.0, != 1.2.3", ">=1.0.0 <2.0.0 <1.2.3 || >=1.0.0 <2.0.0 >1.2.3"},
+		{
+			"^1.0.0, != 1.1.0, != 1.2.0, != 1.3.0, != 1.4.0, != 1.5.0",
+			">=1.0.0 <2.0.0 <1.1.0 || >=1.0.0 <2.0.0 >1.1.0 <1.2.0 || >=1.0.0 <2.0.0 >1.2.0 <1.3.0 || " +
+				">=1.0.0 <2.0.0 >1.3.0 <1.4.0 || >=1.0.0 <2.0.0 >1.4.0 <1.5.0 || >=1.0.0 <2.0.0 >1.5.0",
+		},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		if a := c.ToNodeSemver(); a != tc.node {
+			t.Errorf("Expected %q to render as %q but got %q", tc.constraint, tc.node, a)
+		}
+	}
+
+	branch, err := NewConstraintWithOptions("main", ConstraintOptions{AllowBranchTokens: []string{"main"}})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, c := range []*Constraints{NoneConstraint(), branch} {
+		if a := c.ToNodeSemver(); a != "<0.0.0-0" {
+			t.Errorf("Expected %q to render as an unsatisfiable range but got %q", c, a)
+		}
+	}
+}
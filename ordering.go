@@ -0,0 +1,37 @@
+package semver
+
+import "fmt"
+
+// Ordering is the result of comparing two versions with Order.
+type Ordering int
+
+const (
+	// OrderLess is given when the version is lower than the other one.
+	OrderLess Ordering = -1
+
+	// OrderEqual is given when the versions are equal.
+	OrderEqual Ordering = 0
+
+	// OrderGreater is given when the version is greater than the other one.
+	OrderGreater Ordering = 1
+)
+
+// String returns the name of the ordering.
+func (o Ordering) String() string {
+	switch o {
+	case OrderLess:
+		return "less"
+	case OrderEqual:
+		return "equal"
+	case OrderGreater:
+		return "greater"
+	default:
+		return fmt.Sprintf("Ordering(%d)", int(o))
+	}
+}
+
+// Order compares this version to another one like Compare does, giving the
+// result as an Ordering rather than -1, 0 or 1.
+func (v *Version) Order(o *Version) Ordering {
+	return Ordering(v.Compare(o))
+}
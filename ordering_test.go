@@ -0,0 +1,32 @@
+package semver
+
+import "testing"
+
+func TestOrder(t *testing.T) {
+	tests := []struct {
+		v1       string
+		v2       string
+		expected Ordering
+	}{
+		{"1.2.3", "1.5.1", OrderLess},
+		{"1.2.3-beta", "1.2.3", OrderLess},
+		{"1.2.3", "1.2.3+build", OrderEqual},
+		{"2.2.3", "1.5.1", OrderGreater},
+		{"1.2.3-beta.11", "1.2.3-beta.2", OrderGreater},
+	}
+
+	for _, tc := range tests {
+		v1, v2 := MustParse(tc.v1), MustParse(tc.v2)
+
+		if a := v1.Order(v2); a != tc.expected {
+			t.Errorf("Expected %s compared to %s to be %s but got %s", tc.v1, tc.v2, tc.expected, a)
+		}
+		if int(v1.Order(v2)) != v1.Compare(v2) {
+			t.Errorf("Expected the ordering of %s and %s to agree with Compare", tc.v1, tc.v2)
+		}
+	}
+
+	if s := Ordering(3).String(); s != "Ordering(3)" {
+		t.Errorf("Expected Ordering(3) but got %s", s)
+	}
+}
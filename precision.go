@@ -0,0 +1,79 @@
+package semver
+
+import "fmt"
+
+// Precision is the number of segments of a version taken into account when
+// comparing versions with CompareAtPrecision.
+type Precision int
+
+const (
+	// PrecisionMajor only compares the major version.
+	PrecisionMajor Precision = iota
+
+	// PrecisionMinor compares the major and minor versions.
+	PrecisionMinor
+
+	// PrecisionPatch compares the major, minor and patch versions.
+	PrecisionPatch
+
+	// PrecisionPrerelease compares the whole version, pre-release included,
+	// like Compare does.
+	PrecisionPrerelease
+)
+
+// String returns the name of the precision.
+func (p Precision) String() string {
+	switch p {
+	case PrecisionMajor:
+		return "major"
+	case PrecisionMinor:
+		return "minor"
+	case PrecisionPatch:
+		return "patch"
+	case PrecisionPrerelease:
+		return "prerelease"
+	default:
+		return fmt.Sprintf("Precision(%d)", int(p))
+	}
+}
+
+// CompareAtPrecision compares this version to another one like Compare does
+// but stops at the given precision. It returns -1, 0, or 1 if the version
+// smaller, equal, or larger than the other version. For example, 1.2.3 and
+// 1.2.9 are equal at PrecisionMinor. The epochs are always compared.
+func (v *Version) CompareAtPrecision(o *Version, p Precision) int {
+	if p >= PrecisionPrerelease {
+		return v.Compare(o)
+	}
+
+	if d := compareSegment(v.epoch, o.epoch); d != 0 {
+		return d
+	}
+	if d := compareSegment(v.Major(), o.Major()); d != 0 || p == PrecisionMajor {
+		return d
+	}
+	if d := compareSegment(v.Minor(), o.Minor()); d != 0 || p == PrecisionMinor {
+		return d
+	}
+
+	return compareSegment(v.Patch(), o.Patch())
+}
+
+// EqualWithin tests if two versions are equal up to the given precision. For
+// example, 1.2.3 and 1.2.9 are equal within PrecisionMinor but not within
+// PrecisionPatch.
+func (v *Version) EqualWithin(o *Version, p Precision) bool {
+	return v.CompareAtPrecision(o, p) == 0
+}
+
+// SameMajor tells if two versions are on the same major release line, such
+// as 1.2.3 and 1.9.0-beta. The pre-releases and the metadata are ignored.
+func (v *Version) SameMajor(o *Version) bool {
+	return v.EqualWithin(o, PrecisionMajor)
+}
+
+// SameMinor tells if two versions are on the same minor release line, such
+// as 1.2.3 and 1.2.9-beta. The pre-releases and the metadata are ignored.
+func (v *Version) SameMinor(o *Version) bool {
+	return v.EqualWithin(o, PrecisionMinor)
+}
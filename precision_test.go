@@ -0,0 +1,94 @@
+package semver
+
+import "testing"
+
+func TestCompareAtPrecision(t *testing.T) {
+	tests := []struct {
+		v1        string
+		v2        string
+		precision Precision
+		expected  int
+	}{
+		{"1.2.3", "1.9.9", PrecisionMajor, 0},
+		{"1.2.3", "2.0.0", PrecisionMajor, -1},
+		{"1.2.3", "1.2.9", PrecisionMinor, 0},
+		{"1.3.0", "1.2.9", PrecisionMinor, 1},
+		{"1.2.3-beta", "1.2.3", PrecisionPatch, 0},
+		{"1.2.3", "1.2.4", PrecisionPatch, -1},
+		{"1.2.3-beta", "1.2.3", PrecisionPrerelease, -1},
+		{"1.2.3+build.1", "1.2.3+build.2", PrecisionPrerelease, 0},
+	}
+
+	for _, tc := range tests {
+		v1 := MustParse(tc.v1)
+		v2 := MustParse(tc.v2)
+
+		if a := v1.CompareAtPrecision(v2, tc.precision); a != tc.expected {
+			t.Errorf(
+				"Comparison of '%s' and '%s' at %s failed. Expected '%d', got '%d'",
+				tc.v1, tc.v2, tc.precision, tc.expected, a,
+			)
+		}
+	}
+
+	// Epochs are compared whatever the precision.
+	v1, _ := NewVersionEpoch("1:1.0.0")
+	if a := v1.CompareAtPrecision(MustParse("1.0.0"), PrecisionMajor); a != 1 {
+		t.Errorf("Expected the epoch to be compared but got '%d'", a)
+	}
+}
+
+func TestEqualWithin(t *testing.T) {
+	tests := []struct {
+		v1        string
+		v2        string
+		precision Precision
+		expected  bool
+	}{
+		{"1.2.3", "1.9.9", PrecisionMajor, true},
+		{"1.2.3", "2.2.3", PrecisionMajor, false},
+		{"1.2.3", "1.2.9", PrecisionMinor, true},
+		{"1.2.3", "1.3.3", PrecisionMinor, false},
+		{"1.2.3-beta", "1.2.3-rc", PrecisionPatch, true},
+		{"1.2.3", "1.2.4", PrecisionPatch, false},
+		{"1.2.3-beta", "1.2.3-beta", PrecisionPrerelease, true},
+		{"1.2.3-beta", "1.2.3-rc", PrecisionPrerelease, false},
+	}
+
+	for _, tc := range tests {
+		v1 := MustParse(tc.v1)
+		v2 := MustParse(tc.v2)
+
+		if a := v1.EqualWithin(v2, tc.precision); a != tc.expected {
+			t.Errorf("Expected %q and %q equal within %s to be %t", tc.v1, tc.v2, tc.precision, tc.expected)
+		}
+	}
+}
+
+func TestSameLine(t *testing.T) {
+	tests := []struct {
+		v1        string
+		v2        string
+		sameMajor bool
+		sameMinor bool
+	}{
+		{"1.2.3", "1.2.9", true, true},
+		{"1.2.3", "1.2.0-beta.1", true, true},
+		{"1.2.3-rc.1+build.5", "1.2.3", true, true},
+		{"1.2.3", "1.9.0-beta", true, false},
+		{"1.2.3", "2.2.3", false, false},
+		{"0.1.0", "0.2.0", true, false},
+	}
+
+	for _, tc := range tests {
+		v1 := MustParse(tc.v1)
+		v2 := MustParse(tc.v2)
+
+		if a := v1.SameMajor(v2); a != tc.sameMajor {
+			t.Errorf("Expected %q and %q same major to be %t", tc.v1, tc.v2, tc.sameMajor)
+		}
+		if a := v1.SameMinor(v2); a != tc.sameMinor {
+			t.Errorf("Expected %q and %q same minor to be %t", tc.v1, tc.v2, tc.sameMinor)
+		}
+	}
+}
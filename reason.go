@@ -0,0 +1,164 @@
+package semver
+
+import "fmt"
+
+// MatchFailReason tells why a version does not satisfy constraints.
+type MatchFailReason int
+
+const (
+	// ReasonNone is given when the version satisfies the constraints.
+	ReasonNone MatchFailReason = iota
+
+	// ReasonOutOfRange is given when the version is outside of the range of
+	// versions the constraints allow.
+	ReasonOutOfRange
+
+	// ReasonPrereleaseExcluded is given when the version is within the range
+	// the constraints allow but is a pre-release the constraints do not opt
+	// in to (e.g., 1.5.0-beta and ^1.0.0).
+	ReasonPrereleaseExcluded
+
+	// ReasonExplicitlyExcluded is given when the version is within the range
+	// the constraints allow but is excluded with `!=`.
+	ReasonExplicitlyExcluded
+)
+
+// String returns the name of the reason.
+func (r MatchFailReason) String() string {
+	switch r {
+	case ReasonNone:
+		return "none"
+	case ReasonOutOfRange:
+		return "out of range"
+	case ReasonPrereleaseExcluded:
+		return "prerelease excluded"
+	case ReasonExplicitlyExcluded:
+		return "explicitly excluded"
+	default:
+		return fmt.Sprintf("MatchFailReason(%d)", int(r))
+	}
+}
+
+// CheckReason tests if a version satisfies the constraints like Check does.
+// When it does not, the reason of the failure is returned. With OR groups
+// the reason closest to a match is given: ReasonPrereleaseExcluded when a
+// group only rejects the version because it is a pre-release, then
+// ReasonExplicitlyExcluded when a group only rejects it with `!=` and
+// ReasonOutOfRange otherwise.
+func (cs Constraints) CheckReason(v *Version) (ok bool, reason MatchFailReason) {
+	if cs.casefold {
+		v = foldPrerelease(v)
+	}
+
+	if cs.floor != nil && v.LessThan(cs.floor) {
+		return false, ReasonOutOfRange
+	}
+
+	if cs.floorPrereleases && cs.floorPrerelease(v) {
+		return true, ReasonNone
+	}
+
+	reason = ReasonOutOfRange
+	for _, o := range cs.constraints {
+		// A group is as far from a match as its furthest failing constraint.
+		gr := ReasonNone
+		if cs.sameCore && !sameCorePrerelease(o, v) {
+			gr = ReasonPrereleaseExcluded
+		}
+		for _, c := range o {
+			r := c.failReason(v)
+			if r != ReasonNone && (gr == ReasonNone || r.closeness() < gr.closeness()) {
+				gr = r
+			}
+		}
+
+		if gr == ReasonNone {
+			return true, ReasonNone
+		}
+		if gr.closeness() > reason.closeness() {
+			reason = gr
+		}
+	}
+
+	return false, reason
+}
+
+// CheckExcluded tells if the version only fails to satisfy the constraints
+// because of a `!=` exclusion, and returns the version of that exclusion. For
+// example, 1.4.2 with `^1.2.0, != 1.4.2` gives 1.4.2 and true. A wildcard
+// exclusion gives the version it is written with, so `!= 1.4.x` gives 1.4.0.
+// With OR groups the exclusion of the first group rejecting the version only
+// with exclusions is returned. A version satisfying the constraints, or failing
+// them for another reason, gives nil and false.
+func (cs Constraints) CheckExcluded(v *Version) (excludedBy *Version, ok bool) {
+	if cs.casefold {
+		v = foldPrerelease(v)
+	}
+
+	if cs.Check(v) || (cs.floor != nil && v.LessThan(cs.floor)) {
+		return nil, false
+	}
+
+	for _, o := range cs.constraints {
+		if cs.sameCore && !sameCorePrerelease(o, v) {
+			continue
+		}
+
+		var by *Version
+		for _, c := range o {
+			r := c.failReason(v)
+			if r == ReasonNone {
+				continue
+			}
+			if r != ReasonExplicitlyExcluded {
+				by = nil
+				break
+			}
+			if by == nil {
+				by = c.con
+			}
+		}
+
+		if by != nil {
+			return by, true
+		}
+	}
+
+	return nil, false
+}
+
+// closeness ranks the reasons by how close to a match the version is.
+func (r MatchFailReason) closeness() int {
+	switch r {
+	case ReasonPrereleaseExcluded:
+		return 2
+	case ReasonExplicitlyExcluded:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// failReason returns why the version does not meet the constraint. A
+// pre-release is only rejected for being a pre-release when both the
+// pre-release and its release are within the range of the constraint. So,
+// 2.0.0-beta is out of the range of ^1.0.0.
+func (c *constraint) failReason(v *Version) MatchFailReason {
+	if c.check(v) {
+		return ReasonNone
+	}
+
+	if c.op == "!=" {
+		if v.Prerelease() != "" && !c.exclusionRange().containsVersion(v.Finalize()) {
+			return ReasonPrereleaseExcluded
+		}
+		return ReasonExplicitlyExcluded
+	}
+
+	r := c.bounds()
+	if v.Prerelease() != "" && r.containsVersion(v) && r.containsVersion(v.Finalize()) {
+		return ReasonPrereleaseExcluded
+	}
+
+	return ReasonOutOfRange
+}
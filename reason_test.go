@@ -0,0 +1,85 @@
+package semver
+
+import "testing"
+
+func TestCheckReason(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		ok         bool
+		reason     MatchFailReason
+	}{
+		{"^1.0.0", "1.5.0", true, ReasonNone},
+		{"^1.0.0", "1.5.0-beta", false, ReasonPrereleaseExcluded},
+		{"^1.0.0", "2.0.0", false, ReasonOutOfRange},
+		{"^1.0.0", "1.0.0-beta", false, ReasonOutOfRange},
+		{"^1.0.0", "2.0.0-beta", false, ReasonOutOfRange},
+		{"^1.0.0-alpha", "1.5.0-beta", true, ReasonNone},
+		{"< 2.0.0", "1.9.0-rc.1", false, ReasonPrereleaseExcluded},
+		{"< 2.0.0", "2.0.0-rc.1", false, ReasonOutOfRange},
+		{"*", "1.0.0-beta", false, ReasonPrereleaseExcluded},
+		{"^1.0.0, != 1.2.3", "1.2.3", false, ReasonExplicitlyExcluded},
+		{"^1.0.0, != 1.2.x", "1.2.5", false, ReasonExplicitlyExcluded},
+		{"^1.0.0, != 1.2.x", "1.3.0-beta", false, ReasonPrereleaseExcluded},
+		{"^1.0.0, != 1.2.x", "1.2.5-beta", false, ReasonExplicitlyExcluded},
+		{"^1.0.0, != 1.2.3", "3.0.0", false, ReasonOutOfRange},
+		{"1.2.3", "1.2.4", false, ReasonOutOfRange},
+		{"^2.0.0 || ^1.0.0, != 1.2.3", "1.2.3", false, ReasonExplicitlyExcluded},
+		{"^1.0.0, != 1.5.0 || ^1.0.0", "1.5.0-beta", false, ReasonPrereleaseExcluded},
+		{"!= 1.5.0 || ^1.0.0", "1.5.0", true, ReasonNone},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		ok, reason := c.CheckReason(MustParse(tc.version))
+		if ok != tc.ok || reason != tc.reason {
+			t.Errorf("Constraint %q with version %q expected %t, %s but got %t, %s",
+				tc.constraint, tc.version, tc.ok, tc.reason, ok, reason)
+		}
+		if ok != c.Check(MustParse(tc.version)) {
+			t.Errorf("Constraint %q with version %q does not agree with Check", tc.constraint, tc.version)
+		}
+	}
+}
+
+func TestCheckExcluded(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		excluded   string
+	}{
+		{"^1.2.0, != 1.4.2, != 1.5.0", "1.4.2", "1.4.2"},
+		{"^1.2.0, != 1.4.2, != 1.5.0", "1.5.0", "1.5.0"},
+		{"^1.2.0, != 1.4.2, != 1.5.0", "1.4.3", ""},
+		{"^1.2.0, != 1.4.2, != 1.5.0", "2.0.0", ""},
+		{"^1.2.0, != 1.4.x", "1.4.7", "1.4.0"},
+		{">= 2.0.0, != 1.4.2", "1.4.2", ""},
+		{"^2.0.0 || ^1.0.0, != 1.4.2", "1.4.2", "1.4.2"},
+		{"^1.0.0, != 1.4.2 || ^1.0.0, != 1.4.3", "1.4.2", ""},
+		{"^1.0.0, != 1.4.2-beta", "1.4.2-beta", ""},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		by, ok := c.CheckExcluded(MustParse(tc.version))
+		if tc.excluded == "" {
+			if ok || by != nil {
+				t.Errorf("Expected %s to not be excluded by %q but got %s", tc.version, tc.constraint, by)
+			}
+			continue
+		}
+		if !ok || by == nil || by.String() != tc.excluded {
+			t.Errorf("Expected %s to be excluded by %s in %q but got %v, %t", tc.version, tc.excluded, tc.constraint, by, ok)
+		}
+	}
+}
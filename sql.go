@@ -0,0 +1,126 @@
+package semver
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToSQL renders the constraints as a parameterized SQL predicate over the
+// integer columns holding the major, minor and patch versions. Placeholders
+// are numbered in the PostgreSQL style ($1, $2, ...) and their values are
+// returned in order. For example, `^1.2.3` gives
+//
+//	((major > $1 OR (major = $2 AND minor > $3) OR (major = $4 AND minor = $5 AND patch >= $6)) AND (major < $7 OR (major = $8 AND minor < $9) OR (major = $10 AND minor = $11 AND patch < $12)))
+//
+// Only the range of each OR group and the versions excluded with `!=` are
+// rendered. Pre-releases and epochs are out of scope: the rows are taken as
+// releases, so `>1.2.3-rc` is rendered as `>=1.2.3` and `<=1.2.3-rc` as
+// `<1.2.3`, and the predicate can not tell a pre-release row from its release. Constraints matching any version give `1 = 1` and constraints
+// without any range, such as NoneConstraint or a branch, give `1 = 0`.
+func (cs Constraints) ToSQL(majorCol, minorCol, patchCol string) (clause string, args []interface{}) {
+	b := &sqlBuilder{cols: [3]string{majorCol, minorCol, patchCol}}
+
+	var ors []string
+	for i, r := range cs.ranges() {
+		// The rows only hold releases: the release of a pre-release bound
+		// is above it, so it is admitted by a lower bound and not by an
+		// upper one.
+		var ands []string
+		if r.min != nil {
+			op := ">"
+			if r.minIncl || r.min.Prerelease() != "" {
+				op = ">="
+			}
+			if !(op == ">=" && r.min.Major() == 0 && r.min.Minor() == 0 && r.min.Patch() == 0) {
+				ands = append(ands, b.compare(op, r.min))
+			}
+		}
+		if r.max != nil {
+			op := "<"
+			if r.maxIncl && r.max.Prerelease() == "" {
+				op = "<="
+			}
+			ands = append(ands, b.compare(op, r.max))
+		}
+
+		for _, c := range cs.constraints[i] {
+			if c.op != "!=" {
+				continue
+			}
+			if !c.dirty {
+				if c.con.Prerelease() == "" {
+					ands = append(ands, "NOT "+b.equal(c.con))
+				}
+				continue
+			}
+
+			e := c.exclusionRange()
+			ands = append(ands, "NOT ("+b.compare(">=", e.min)+" AND "+b.compare("<", e.max)+")")
+		}
+
+		switch len(ands) {
+		case 0:
+			ors = append(ors, "1 = 1")
+		case 1:
+			ors = append(ors, ands[0])
+		default:
+			ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+		}
+	}
+
+	switch len(ors) {
+	case 0:
+		return "1 = 0", nil
+	case 1:
+		return ors[0], b.args
+	}
+
+	return "(" + strings.Join(ors, " OR ") + ")", b.args
+}
+
+// sqlBuilder collects the arguments of the SQL predicate being rendered.
+type sqlBuilder struct {
+	cols [3]string
+	args []interface{}
+}
+
+// arg adds an argument and returns its placeholder.
+func (b *sqlBuilder) arg(v interface{}) string {
+	b.args = append(b.args, v)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// compare renders the comparison of the columns to the version with the op
+// operator, one of >, >=, < and <=. The columns are compared in order: a
+// greater major wins whatever the minor and patch versions are.
+func (b *sqlBuilder) compare(op string, v *Version) string {
+	strict := op[:1]
+	segs := [3]int64{v.Major(), v.Minor(), v.Patch()}
+
+	alts := make([]string, 3)
+	for i := range alts {
+		var conds []string
+		for j := 0; j < i; j++ {
+			conds = append(conds, b.cols[j]+" = "+b.arg(segs[j]))
+		}
+
+		o := strict
+		if i == 2 {
+			o = op
+		}
+		conds = append(conds, b.cols[i]+" "+o+" "+b.arg(segs[i]))
+
+		alts[i] = strings.Join(conds, " AND ")
+		if i > 0 {
+			alts[i] = "(" + alts[i] + ")"
+		}
+	}
+
+	return "(" + strings.Join(alts, " OR ") + ")"
+}
+
+// equal renders the equality of the columns to the version.
+func (b *sqlBuilder) equal(v *Version) string {
+	return fmt.Sprintf("(%s = %s AND %s = %s AND %s = %s)",
+		b.cols[0], b.arg(v.Major()), b.cols[1], b.arg(v.Minor()), b.cols[2], b.arg(v.Patch()))
+}
@@ -0,0 +1,241 @@
+package semver
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestToSQL(t *testing.T) {
+	tests := []struct {
+		constraint string
+		clause     string
+		args       []interface{}
+	}{
+		{
+			"^1.2.3",
+			"((ma > $1 OR (ma = $2 AND mi > $3) OR (ma = $4 AND mi = $5 AND pa >= $6)) AND " +
+				"(ma < $7 OR (ma = $8 AND mi < $9) OR (ma = $10 AND mi = $11 AND pa < $12)))",
+			[]interface{}{int64(1), int64(1), int64(2), int64(1), int64(2), int64(3),
+				int64(2), int64(2), int64(0), int64(2), int64(0), int64(0)},
+		},
+		{
+			"> 1.2.3",
+			"(ma > $1 OR (ma = $2 AND mi > $3) OR (ma = $4 AND mi = $5 AND pa > $6))",
+			[]interface{}{int64(1), int64(1), int64(2), int64(1), int64(2), int64(3)},
+		},
+		{
+			"<= 2.0.0 || *",
+			"((ma < $1 OR (ma = $2 AND mi < $3) OR (ma = $4 AND mi = $5 AND pa <= $6)) OR 1 = 1)",
+			[]interface{}{int64(2), int64(2), int64(0), int64(2), int64(0), int64(0)},
+		},
+		{
+			"!= 1.2.3",
+			"NOT (ma = $1 AND mi = $2 AND pa = $3)",
+			[]interface{}{int64(1), int64(2), int64(3)},
+		},
+		{
+			">= 0.0.0",
+			"1 = 1",
+			nil,
+		},
+		{
+			"> 1.2.3-rc",
+			"(ma > $1 OR (ma = $2 AND mi > $3) OR (ma = $4 AND mi = $5 AND pa >= $6))",
+			[]interface{}{int64(1), int64(1), int64(2), int64(1), int64(2), int64(3)},
+		},
+		{
+			"<= 1.2.3-rc",
+			"(ma < $1 OR (ma = $2 AND mi < $3) OR (ma = $4 AND mi = $5 AND pa < $6))",
+			[]interface{}{int64(1), int64(1), int64(2), int64(1), int64(2), int64(3)},
+		},
+		{
+			">= 0.0.0-0",
+			"1 = 1",
+			nil,
+		},
+	}
+
+	for _, tc := range tests {
+		c, err := NewConstraint(tc.constraint)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		clause, args := c.ToSQL("ma", "mi", "pa")
+		if clause != tc.clause {
+			t.Errorf("Constraint %q expected clause\n%s\nbut got\n%s", tc.constraint, tc.clause, clause)
+		}
+		if !reflect.DeepEqual(args, tc.args) {
+			t.Errorf("Constraint %q expected args %v but got %v", tc.constraint, tc.args, args)
+		}
+	}
+
+	branch, err := NewConstraintWithOptions("main", ConstraintOptions{AllowBranchTokens: []string{"main"}})
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	for _, c := range []*Constraints{NoneConstraint(), branch} {
+		if clause, args := c.ToSQL("ma", "mi", "pa"); clause != "1 = 0" || args != nil {
+			t.Errorf("Expected %q to give a false predicate but got %s with %v", c, clause, args)
+		}
+	}
+}
+
+// TestToSQLEvaluate evaluates the rendered predicates against release
+// versions and compares the outcome to Check.
+func TestToSQLEvaluate(t *testing.T) {
+	constraints := []string{
+		"^1.2.3",
+		"~1.2.3",
+		"1.2.x",
+		"> 1.2.3, < 2.1",
+		"<= 1.x",
+		"1.2.3 || >= 3.0.0",
+		"^1.0.0, != 1.2.3, != 1.4.x",
+		"*",
+		"> 1.2.3-rc",
+		">= 1.2.3-rc, < 2.0.0-0",
+		"<= 1.2.3-rc",
+	}
+
+	var versions []*Version
+	for ma := int64(0); ma < 4; ma++ {
+		for mi := int64(0); mi < 6; mi++ {
+			for pa := int64(0); pa < 5; pa++ {
+				versions = append(versions, newVersion(ma, mi, pa))
+			}
+		}
+	}
+
+	for _, s := range constraints {
+		c, err := NewConstraint(s)
+		if err != nil {
+			t.Errorf("err: %s", err)
+			continue
+		}
+
+		clause, args := c.ToSQL("ma", "mi", "pa")
+		for _, v := range versions {
+			e := evalSQL(t, clause, args, v)
+			if a := c.Check(v); a != e {
+				t.Errorf("Constraint %q and version %s: Check gives %t but SQL gives %t", s, v, a, e)
+			}
+		}
+	}
+}
+
+// evalSQL evaluates the small SQL dialect rendered by ToSQL.
+func evalSQL(t *testing.T, clause string, args []interface{}, v *Version) bool {
+	p := &sqlEval{toks: sqlTokens(clause), args: args, v: v}
+	r := p.or()
+	if p.pos != len(p.toks) {
+		t.Fatalf("Unexpected token %q in %q", p.toks[p.pos], clause)
+	}
+	return r
+}
+
+type sqlEval struct {
+	toks []string
+	pos  int
+	args []interface{}
+	v    *Version
+}
+
+func sqlTokens(s string) []string {
+	var toks []string
+	cur := ""
+	for _, r := range s {
+		switch r {
+		case ' ':
+			if cur != "" {
+				toks, cur = append(toks, cur), ""
+			}
+		case '(', ')':
+			if cur != "" {
+				toks, cur = append(toks, cur), ""
+			}
+			toks = append(toks, string(r))
+		default:
+			cur += string(r)
+		}
+	}
+	if cur != "" {
+		toks = append(toks, cur)
+	}
+	return toks
+}
+
+func (p *sqlEval) next() string {
+	t := p.toks[p.pos]
+	p.pos++
+	return t
+}
+
+func (p *sqlEval) peek(s string) bool {
+	return p.pos < len(p.toks) && p.toks[p.pos] == s
+}
+
+func (p *sqlEval) or() bool {
+	r := p.and()
+	for p.peek("OR") {
+		p.next()
+		r = p.and() || r
+	}
+	return r
+}
+
+func (p *sqlEval) and() bool {
+	r := p.unary()
+	for p.peek("AND") {
+		p.next()
+		r = p.unary() && r
+	}
+	return r
+}
+
+func (p *sqlEval) unary() bool {
+	if p.peek("NOT") {
+		p.next()
+		return !p.unary()
+	}
+	if p.peek("(") {
+		p.next()
+		r := p.or()
+		p.next()
+		return r
+	}
+
+	l, op, r := p.value(p.next()), p.next(), p.value(p.next())
+	switch op {
+	case "=":
+		return l == r
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	default:
+		return l >= r
+	}
+}
+
+func (p *sqlEval) value(s string) int64 {
+	switch s {
+	case "ma":
+		return p.v.Major()
+	case "mi":
+		return p.v.Minor()
+	case "pa":
+		return p.v.Patch()
+	case "1":
+		return 1
+	}
+
+	var i int
+	for _, r := range s[1:] {
+		i = i*10 + int(r-'0')
+	}
+	return p.args[i-1].(int64)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,7 +13,6 @@ import (
 
 // The compiled version of the regex created at init() is cached here so it
 // only needs to be created once.
-var versionRegex *regexp.Regexp
 var validPrereleaseRegex *regexp.Regexp
 
 var (
@@ -25,10 +25,14 @@ var (
 
 	// ErrInvalidPrerelease is returned when the pre-release is an invalid format
 	ErrInvalidPrerelease = errors.New("Invalid Prerelease string")
+
+	// ErrPrereleaseSet is returned when starting a pre-release from a version
+	// that already is one
+	ErrPrereleaseSet = errors.New("Version already has a Prerelease")
 )
 
 // SemVerRegex is the regular expression used to parse a semantic version.
-const SemVerRegex string = `v?([0-9]+)(\.[0-9]+)?(\.[0-9]+)?` +
+const SemVerRegex string = `[vV]?([0-9]+)(\.[0-9]+)?(\.[0-9]+)?` +
 	`(-([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?` +
 	`(\+([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*))?`
 
@@ -36,8 +40,34 @@ const SemVerRegex string = `v?([0-9]+)(\.[0-9]+)?(\.[0-9]+)?` +
 // both prerelease and metadata values.
 const ValidPrerelease string = `^([0-9A-Za-z\-]+(\.[0-9A-Za-z\-]+)*)`
 
+// ParseError is returned when a version can not be parsed. It records which
+// segment of the version was being parsed when the failure happened.
+type ParseError struct {
+	// Version is the string that was being parsed.
+	Version string
+
+	// Segment is the part of the version that failed to parse. It is one of
+	// epoch, major, minor, patch, prerelease or metadata, or version when no
+	// single segment is at fault.
+	Segment string
+
+	// Err is the reason of the failure.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("Error parsing %s segment of %q: %s", e.Segment, e.Version, e.Err)
+}
+
 // Version represents a single semantic version.
+//
+// A Version is immutable and safe to copy by value. It is a comparable type
+// so it can be used as a map key. Note that map keys compare every field,
+// including the metadata and the original string, so 1.2.3 and v1.2.3 are
+// distinct keys even though they are equal versions.
 type Version struct {
+	epoch               int64
 	major, minor, patch int64
 	pre                 string
 	metadata            string
@@ -45,54 +75,152 @@ type Version struct {
 }
 
 func init() {
-	versionRegex = regexp.MustCompile("^" + SemVerRegex + "$")
 	validPrereleaseRegex = regexp.MustCompile(ValidPrerelease)
 }
 
 // NewVersion parses a given version and returns an instance of Version or
-// an error if unable to parse the version.
+// an error if unable to parse the version. The version may start with v or V
+// and spaces around it are ignored, the Original() method still gives it as
+// passed in. Spaces within the version are not accepted. See SetStrict to
+// require the three numeric segments without a v.
 func NewVersion(v string) (*Version, error) {
-	m := versionRegex.FindStringSubmatch(v)
-	if m == nil {
+	return parseVersion(v, strictMode)
+}
+
+// strictMode is set with SetStrict.
+var strictMode bool
+
+// SetStrict sets whether NewVersion, and the functions documented to behave
+// like it, only accept versions with a major, minor and patch version and no
+// leading v, such as 1.2.3 but not v1.2.3 or 1.2. Constraints and the parsers
+// of other formats, such as NewVersionLenient and ParseGoListVersions, are
+// not affected. Versions are not strict by default.
+//
+// The setting is global and read without synchronization: set it once, when
+// the program starts, before any version is parsed.
+func SetStrict(strict bool) {
+	strictMode = strict
+}
+
+// parseVersion parses a version like NewVersion does. When strict is set the
+// v prefix and the short forms, such as 1.2, are rejected.
+func parseVersion(v string, strict bool) (*Version, error) {
+	// The version is scanned by hand rather than with SemVerRegex, which
+	// describes the same grammar but is much slower to match.
+	s := strings.TrimSpace(v)
+	if s != "" && (s[0] == 'v' || s[0] == 'V') {
+		if strict {
+			return nil, ErrInvalidSemVer
+		}
+		s = s[1:]
+	}
+
+	// Up to three dot separated numeric segments come first.
+	var segs [3]string
+	i := 0
+	for n := 0; n < len(segs); n++ {
+		j := i
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		if j == i {
+			return nil, ErrInvalidSemVer
+		}
+		segs[n] = s[i:j]
+		i = j
+
+		if i == len(s) || s[i] != '.' || n == len(segs)-1 {
+			break
+		}
+		i++
+	}
+	if strict && segs[2] == "" {
 		return nil, ErrInvalidSemVer
 	}
 
-	sv := &Version{
-		metadata: m[8],
-		pre:      m[5],
-		original: v,
+	sv := &Version{original: v}
+
+	rest := s[i:]
+	if rest != "" && rest[0] == '-' {
+		end := strings.IndexByte(rest, '+')
+		if end < 0 {
+			end = len(rest)
+		}
+		if !isValidIdentifiers(rest[1:end]) {
+			return nil, ErrInvalidSemVer
+		}
+		sv.pre = rest[1:end]
+		rest = rest[end:]
+	}
+	if rest != "" && rest[0] == '+' {
+		if !isValidIdentifiers(rest[1:]) {
+			// Only the first + starts the metadata. Another one can not be
+			// part of it and is pointed at rather than reported as a
+			// generic failure.
+			if strings.IndexByte(rest[1:], '+') >= 0 {
+				first := strings.IndexByte(v, '+')
+				second := first + 1 + strings.IndexByte(v[first+1:], '+')
+				return nil, &ParseError{v, "metadata", fmt.Errorf("illegal second + at offset %d", second)}
+			}
+			return nil, ErrInvalidSemVer
+		}
+		sv.metadata = rest[1:]
+		rest = ""
+	}
+	if rest != "" {
+		return nil, ErrInvalidSemVer
 	}
 
-	var temp int64
-	temp, err := strconv.ParseInt(m[1], 10, 64)
+	// The segments are made of digits. Parsing them can still fail when they
+	// are too large to fit in 64 bits.
+	var err error
+	sv.major, err = strconv.ParseInt(segs[0], 10, 64)
 	if err != nil {
-		return nil, fmt.Errorf("Error parsing version segment: %s", err)
+		return nil, &ParseError{v, "major", err}
 	}
-	sv.major = temp
 
-	if m[2] != "" {
-		temp, err = strconv.ParseInt(strings.TrimPrefix(m[2], "."), 10, 64)
+	if segs[1] != "" {
+		sv.minor, err = strconv.ParseInt(segs[1], 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("Error parsing version segment: %s", err)
+			return nil, &ParseError{v, "minor", err}
 		}
-		sv.minor = temp
-	} else {
-		sv.minor = 0
 	}
 
-	if m[3] != "" {
-		temp, err = strconv.ParseInt(strings.TrimPrefix(m[3], "."), 10, 64)
+	if segs[2] != "" {
+		sv.patch, err = strconv.ParseInt(segs[2], 10, 64)
 		if err != nil {
-			return nil, fmt.Errorf("Error parsing version segment: %s", err)
+			return nil, &ParseError{v, "patch", err}
 		}
-		sv.patch = temp
-	} else {
-		sv.patch = 0
 	}
 
 	return sv, nil
 }
 
+// isValidIdentifiers tells if s is made of dot separated identifiers of ASCII
+// letters, digits and hyphens, as used by pre-releases and metadata.
+func isValidIdentifiers(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	empty := true
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '.':
+			if empty {
+				return false
+			}
+			empty = true
+		case c >= '0' && c <= '9', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c == '-':
+			empty = false
+		default:
+			return false
+		}
+	}
+
+	return !empty
+}
+
 // MustParse parses a given version and panics on error.
 func MustParse(v string) *Version {
 	sv, err := NewVersion(v)
@@ -102,6 +230,187 @@ func MustParse(v string) *Version {
 	return sv
 }
 
+// New returns the version made of the given components, such as one stored
+// as integer columns in a database. The pre-release and the metadata are
+// given without their - and + prefixes and may be empty. ErrInvalidPrerelease
+// or ErrInvalidMetadata is returned when they are not dot separated
+// identifiers of letters, digits and hyphens. Components above the largest
+// int64 are an error too.
+func New(major, minor, patch uint64, prerelease, metadata string) (*Version, error) {
+	for i, n := range [3]uint64{major, minor, patch} {
+		if n > math.MaxInt64 {
+			return nil, fmt.Errorf("%s version %d is too large", [3]string{"major", "minor", "patch"}[i], n)
+		}
+	}
+	if prerelease != "" && !isValidIdentifiers(prerelease) {
+		return nil, ErrInvalidPrerelease
+	}
+	if metadata != "" && !isValidIdentifiers(metadata) {
+		return nil, ErrInvalidMetadata
+	}
+
+	v := &Version{
+		major:    int64(major),
+		minor:    int64(minor),
+		patch:    int64(patch),
+		pre:      prerelease,
+		metadata: metadata,
+	}
+	v.original = v.String()
+
+	return v, nil
+}
+
+// NewVersionEpoch parses a given version which may start with a Debian style
+// epoch, such as the 2 in `2:1.2.3`. A version with a higher epoch is always
+// greater than a version with a lower epoch, regardless of the rest of the
+// version. Without an epoch it behaves like NewVersion.
+func NewVersionEpoch(v string) (*Version, error) {
+	var epoch int64
+	rest := v
+	if i := strings.Index(v, ":"); i >= 0 {
+		e, err := parseSegment(v[:i])
+		if err != nil {
+			return nil, &ParseError{v, "epoch", err}
+		}
+		epoch = e
+		rest = v[i+1:]
+	}
+
+	sv, err := NewVersion(rest)
+	if err != nil {
+		return nil, err
+	}
+	sv.epoch = epoch
+	sv.original = v
+
+	return sv, nil
+}
+
+// NewVersionSep parses a given version whose major, minor and patch versions
+// are separated by sep rather than dots, such as 1_2_3 with an underscore.
+// The pre-release and the metadata are still introduced by - and + and their
+// identifiers separated by dots. Otherwise it behaves like NewVersion, the
+// Original() method giving the version as passed in.
+func NewVersionSep(s string, sep rune) (*Version, error) {
+	if sep == '-' || sep == '+' {
+		return nil, fmt.Errorf("invalid separator %q", sep)
+	}
+
+	core := s
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		core = s[:i]
+	}
+	if sep != '.' && strings.Contains(core, ".") {
+		return nil, ErrInvalidSemVer
+	}
+
+	sv, err := NewVersion(strings.Replace(core, string(sep), ".", -1) + s[len(core):])
+	if err != nil {
+		return nil, err
+	}
+	sv.original = s
+
+	return sv, nil
+}
+
+// NewVersionLenient parses a given version like NewVersion does, accepting the
+// trailing junk of machine generated versions: a trailing dot, as in 1.2.3.,
+// is trimmed and a zero fourth segment, as in 1.2.3.0, is dropped. A non zero
+// fourth segment, as in 1.2.3.4, can not be dropped without losing it and is
+// an error. The Original() method gives the version as passed in.
+func NewVersionLenient(v string) (*Version, error) {
+	s := strings.TrimSpace(v)
+	core := s
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		core = s[:i]
+	}
+	rest := s[len(core):]
+
+	core = strings.TrimSuffix(core, ".")
+	if parts := strings.Split(core, "."); len(parts) == 4 {
+		if parts[3] == "" || strings.Trim(parts[3], "0") != "" {
+			return nil, &ParseError{v, "version", fmt.Errorf("too many segments, the fourth one %q is not zero", parts[3])}
+		}
+		core = strings.Join(parts[:3], ".")
+	}
+
+	sv, err := parseVersion(core+rest, false)
+	if err != nil {
+		return nil, err
+	}
+	sv.original = v
+
+	return sv, nil
+}
+
+// TryParse parses a given version like NewVersion does. When the version
+// can not be parsed the returned error tells which segment failed and the
+// returned Version carries the segments successfully parsed before it.
+// For example, "1.2.beta" returns a version with the major and minor set
+// to 1 and 2 along with an error on the patch segment.
+func TryParse(s string) (*Version, *ParseError) {
+	if v, err := NewVersion(s); err == nil {
+		return v, nil
+	}
+
+	sv := &Version{original: s}
+
+	// Split the pre-release and the metadata off the numeric segments. The
+	// metadata comes last and may contain hyphens so it is removed first.
+	core := strings.TrimSpace(s)
+	if strings.HasPrefix(core, "v") || strings.HasPrefix(core, "V") {
+		core = core[1:]
+	}
+	meta, hasMeta := "", false
+	if i := strings.Index(core, "+"); i >= 0 {
+		core, meta, hasMeta = core[:i], core[i+1:], true
+	}
+	pre, hasPre := "", false
+	if i := strings.Index(core, "-"); i >= 0 {
+		core, pre, hasPre = core[:i], core[i+1:], true
+	}
+
+	segments := []string{"major", "minor", "patch"}
+	for i, p := range strings.Split(core, ".") {
+		if i >= len(segments) {
+			return sv, &ParseError{s, "version", fmt.Errorf("too many segments, unexpected %q", p)}
+		}
+
+		n, err := parseSegment(p)
+		if err != nil {
+			return sv, &ParseError{s, segments[i], err}
+		}
+
+		switch i {
+		case 0:
+			sv.major = n
+		case 1:
+			sv.minor = n
+		case 2:
+			sv.patch = n
+		}
+	}
+
+	if hasPre {
+		if !isValidIdentifiers(pre) {
+			return sv, &ParseError{s, "prerelease", ErrInvalidPrerelease}
+		}
+		sv.pre = pre
+	}
+
+	if hasMeta {
+		if !isValidIdentifiers(meta) {
+			return sv, &ParseError{s, "metadata", ErrInvalidMetadata}
+		}
+		sv.metadata = meta
+	}
+
+	// The segments look fine on their own but the version as a whole did not
+	// parse.
+	return sv, &ParseError{s, "version", ErrInvalidSemVer}
+}
+
 // String converts a Version object to a string.
 // Note, if the original version contained a leading v this version will not.
 // See the Original() method to retrieve the original value. Semantic Versions
@@ -110,6 +419,9 @@ func MustParse(v string) *Version {
 func (v *Version) String() string {
 	var buf bytes.Buffer
 
+	if v.epoch != 0 {
+		fmt.Fprintf(&buf, "%d:", v.epoch)
+	}
 	fmt.Fprintf(&buf, "%d.%d.%d", v.major, v.minor, v.patch)
 	if v.pre != "" {
 		fmt.Fprintf(&buf, "-%s", v.pre)
@@ -121,11 +433,39 @@ func (v *Version) String() string {
 	return buf.String()
 }
 
+// ShortString converts a Version object to its shortest string for display.
+// A patch version of 0 is left out, as is a minor version of 0 when the patch
+// version is left out too. So, 1.2.0 gives 1.2 and 1.0.0 gives 1. Versions
+// with a pre-release or metadata are given in full, like String does.
+func (v *Version) ShortString() string {
+	if v.pre != "" || v.metadata != "" || v.patch != 0 {
+		return v.String()
+	}
+
+	var buf bytes.Buffer
+
+	if v.epoch != 0 {
+		fmt.Fprintf(&buf, "%d:", v.epoch)
+	}
+	fmt.Fprintf(&buf, "%d", v.major)
+	if v.minor != 0 {
+		fmt.Fprintf(&buf, ".%d", v.minor)
+	}
+
+	return buf.String()
+}
+
 // Original returns the original value passed in to be parsed.
 func (v *Version) Original() string {
 	return v.original
 }
 
+// Epoch returns the epoch of the version. It is 0 unless the version was
+// parsed by NewVersionEpoch with an epoch.
+func (v *Version) Epoch() uint64 {
+	return uint64(v.epoch)
+}
+
 // Major returns the major version.
 func (v *Version) Major() int64 {
 	return v.major
@@ -141,6 +481,17 @@ func (v *Version) Patch() int64 {
 	return v.patch
 }
 
+// Segments returns the major, minor and patch versions, in that order.
+func (v *Version) Segments() [3]int64 {
+	return [3]int64{v.major, v.minor, v.patch}
+}
+
+// IsUnstableMajor tells if the major version is 0. Such versions are still in
+// initial development and may make breaking changes in a minor release.
+func (v *Version) IsUnstableMajor() bool {
+	return v.major == 0
+}
+
 // Prerelease returns the pre-release version.
 func (v *Version) Prerelease() string {
 	return v.pre
@@ -151,12 +502,52 @@ func (v *Version) Metadata() string {
 	return v.metadata
 }
 
+// PrereleaseIdentifiers returns the dot separated identifiers of the
+// pre-release, such as alpha and 1 for 1.2.3-alpha.1. The slice is empty, not
+// nil, when there is no pre-release. Changing it does not change the version.
+func (v *Version) PrereleaseIdentifiers() []string {
+	return identifiers(v.pre)
+}
+
+// MetadataIdentifiers returns the dot separated identifiers of the metadata,
+// such as build and 5 for 1.2.3+build.5, like PrereleaseIdentifiers does.
+func (v *Version) MetadataIdentifiers() []string {
+	return identifiers(v.metadata)
+}
+
+func identifiers(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	return strings.Split(s, ".")
+}
+
+// IsZero tells if the version is 0.0.0 without a pre-release or metadata,
+// which is the value of an unset Version. A nil Version is zero as well.
+func (v *Version) IsZero() bool {
+	return v == nil || (v.epoch == 0 && v.major == 0 && v.minor == 0 &&
+		v.patch == 0 && v.pre == "" && v.metadata == "")
+}
+
+// MajorMinor returns the major and minor versions joined by a dot (e.g., 1.2).
+func (v *Version) MajorMinor() string {
+	return strconv.FormatInt(v.major, 10) + "." + strconv.FormatInt(v.minor, 10)
+}
+
+// MajorMinorPatch returns the major, minor and patch versions joined by dots
+// (e.g., 1.2.3). Unlike String() it never includes the pre-release or the
+// metadata.
+func (v *Version) MajorMinorPatch() string {
+	return v.MajorMinor() + "." + strconv.FormatInt(v.patch, 10)
+}
+
 // originalVPrefix returns the original 'v' prefix if any.
 func (v *Version) originalVPrefix() string {
 
-	// Note, only lowercase v is supported as a prefix by the parser.
-	if v.original != "" && v.original[:1] == "v" {
-		return v.original[:1]
+	// The prefix is kept as written, in lowercase or uppercase.
+	o := strings.TrimSpace(v.original)
+	if o != "" && (o[:1] == "v" || o[:1] == "V") {
+		return o[:1]
 	}
 	return ""
 }
@@ -240,6 +631,36 @@ func (v Version) SetMetadata(metadata string) (Version, error) {
 	return vNext, nil
 }
 
+// StartPrerelease produces the first pre-release of the version with the
+// given label. For example, 1.3.0 with the label rc gives 1.3.0-rc.1. The
+// metadata is unset. An error is returned when the label is not a valid
+// pre-release or when the version already is a pre-release.
+func (v *Version) StartPrerelease(label string) (*Version, error) {
+	if v.pre != "" {
+		return nil, ErrPrereleaseSet
+	}
+	if !isValidIdentifiers(label) {
+		return nil, ErrInvalidPrerelease
+	}
+
+	vNext := *v
+	vNext.metadata = ""
+	vNext.pre = label + ".1"
+	vNext.original = v.originalVPrefix() + "" + vNext.String()
+	return &vNext, nil
+}
+
+// Finalize produces the release a pre-release is converging toward by
+// unsetting the prerelease and metadata values. For example, 1.3.0-rc.2
+// gives 1.3.0. A version without a pre-release gives an equal copy.
+func (v *Version) Finalize() *Version {
+	vNext := *v
+	vNext.metadata = ""
+	vNext.pre = ""
+	vNext.original = v.originalVPrefix() + "" + vNext.String()
+	return &vNext
+}
+
 // LessThan tests if one version is less than another one.
 func (v *Version) LessThan(o *Version) bool {
 	return v.Compare(o) < 0
@@ -250,6 +671,18 @@ func (v *Version) GreaterThan(o *Version) bool {
 	return v.Compare(o) > 0
 }
 
+// Before tests if one version is less than another one. It is the same as
+// LessThan, named after the time.Time method.
+func (v *Version) Before(o *Version) bool {
+	return v.LessThan(o)
+}
+
+// After tests if one version is greater than another one. It is the same as
+// GreaterThan, named after the time.Time method.
+func (v *Version) After(o *Version) bool {
+	return v.GreaterThan(o)
+}
+
 // Equal tests if two versions are equal to each other.
 // Note, versions can be equal with different metadata since metadata
 // is not considered part of the comparable version.
@@ -257,12 +690,45 @@ func (v *Version) Equal(o *Version) bool {
 	return v.Compare(o) == 0
 }
 
+// CompatibleWith tests if a program expecting this version can safely use
+// the other one. This follows the caret rules: the other version must not be
+// lower and it must have the same major version. For 0.x versions the minor
+// version must match as well, and for 0.0.x versions the patch must match.
+// A pre-release is only compatible when this version is a pre-release too,
+// and versions with a different epoch are never compatible.
+func (v *Version) CompatibleWith(o *Version) bool {
+	if o.Prerelease() != "" && v.Prerelease() == "" {
+		return false
+	}
+
+	if o.LessThan(v) || o.epoch != v.epoch {
+		return false
+	}
+
+	if v.Major() != o.Major() {
+		return false
+	}
+	if v.Major() == 0 && v.Minor() != o.Minor() {
+		return false
+	}
+	if v.Major() == 0 && v.Minor() == 0 && v.Patch() != o.Patch() {
+		return false
+	}
+
+	return true
+}
+
 // Compare compares this version to another one. It returns -1, 0, or 1 if
 // the version smaller, equal, or larger than the other version.
 //
 // Versions are compared by X.Y.Z. Build metadata is ignored. Prerelease is
-// lower than the version without a prerelease.
+// lower than the version without a prerelease. A greater epoch always makes
+// for a greater version.
 func (v *Version) Compare(o *Version) int {
+	if d := compareSegment(v.epoch, o.epoch); d != 0 {
+		return d
+	}
+
 	// Compare the major, minor, and patch version for differences. If a
 	// difference is found return the comparison.
 	if d := compareSegment(v.Major(), o.Major()); d != 0 {
@@ -292,16 +758,146 @@ func (v *Version) Compare(o *Version) int {
 	return comparePrerelease(ps, po)
 }
 
+// CompareFold compares this version to another one like Compare does, the
+// letters of the pre-releases being compared without regard to case. So,
+// 1.2.0-RC.1 and 1.2.0-rc.1 are equal. Semantic versioning compares them with
+// case, this is for the tools emitting pre-releases inconsistently cased.
+func (v *Version) CompareFold(o *Version) int {
+	return foldPrerelease(v).Compare(foldPrerelease(o))
+}
+
+// EqualFold tests if two versions are equal like Equal does, the letters of
+// the pre-releases being compared without regard to case.
+func (v *Version) EqualFold(o *Version) bool {
+	return v.CompareFold(o) == 0
+}
+
+// foldPrerelease returns the version with its pre-release in lower case.
+func foldPrerelease(v *Version) *Version {
+	if v.pre == "" {
+		return v
+	}
+
+	f := *v
+	f.pre = strings.ToLower(v.pre)
+	return &f
+}
+
+// OnlyMetadataDiffers tests if two versions only differ by their build
+// metadata, such as 1.0.0+build.1 and 1.0.0+build.2. This usually stands for a
+// rebuild rather than an upgrade. Versions with the same metadata do not
+// differ at all and give false.
+func (v *Version) OnlyMetadataDiffers(o *Version) bool {
+	return v.Compare(o) == 0 && v.metadata != o.Metadata()
+}
+
+// CompareBuild compares this version to another one like Compare does but
+// orders versions that Compare finds equal by their build metadata. The
+// metadata identifiers are compared with the rules used for pre-releases, so
+// 1.0.0+build.47 is lower than 1.0.0+build.48. A version without metadata is
+// lower than one with metadata. This ordering is not part of the semver spec,
+// which says build metadata is to be ignored.
+func (v *Version) CompareBuild(o *Version) int {
+	if d := v.Compare(o); d != 0 {
+		return d
+	}
+
+	ms := v.metadata
+	mo := o.Metadata()
+
+	if ms == mo {
+		return 0
+	}
+	if ms == "" {
+		return -1
+	}
+	if mo == "" {
+		return 1
+	}
+
+	return comparePrerelease(ms, mo)
+}
+
+// SortKey returns a string whose byte ordering is the ordering of versions,
+// for stores only able to sort strings. The epoch, major, minor and patch
+// versions are zero-padded to 20 digits and separated by dots. A release
+// ends with `~` while a pre-release follows with `-` and its identifiers, so
+// a release sorts after its pre-releases. The identifiers are separated by
+// `!`. Numeric identifiers, made of digits only, are prefixed with 0 and
+// zero-padded to 20 digits, others are prefixed with 1 so they sort after
+// numeric ones. Versions only differing by their metadata share the same key.
+// The ranks set with SetPrereleaseRank are not taken into account.
+func (v *Version) SortKey() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%020d.%020d.%020d.%020d", v.epoch, v.major, v.minor, v.patch)
+	if v.pre == "" {
+		buf.WriteString("~")
+		return buf.String()
+	}
+
+	buf.WriteString("-")
+	for i, p := range strings.Split(v.pre, ".") {
+		if i > 0 {
+			buf.WriteString("!")
+		}
+		if n, err := parseSegment(p); err == nil {
+			fmt.Fprintf(&buf, "0%020d", n)
+		} else {
+			buf.WriteString("1" + p)
+		}
+	}
+
+	return buf.String()
+}
+
+// Compare compares two versions held by value. It returns -1, 0, or 1 like
+// the Compare method does.
+func Compare(a, b Version) int {
+	return a.Compare(&b)
+}
+
+// EqualStrings parses two versions and tells if they are equal. The versions
+// are parsed like NewVersion does, so "v1.2" and "1.2.0" are equal. An error
+// is returned when either of them can not be parsed.
+func EqualStrings(a, b string) (bool, error) {
+	va, err := NewVersion(a)
+	if err != nil {
+		return false, err
+	}
+
+	vb, err := NewVersion(b)
+	if err != nil {
+		return false, err
+	}
+
+	return va.Equal(vb), nil
+}
+
+// lenientJSON is set with SetLenientJSON.
+var lenientJSON bool
+
+// SetLenientJSON sets whether UnmarshalJSON accepts JSON numbers in addition
+// to strings. A number is read as the version it is written as, so 3 gives
+// 3.0.0 and 3.1 gives 3.1.0. Numbers are rejected by default.
+func SetLenientJSON(lenient bool) {
+	lenientJSON = lenient
+}
+
 // UnmarshalJSON implements JSON.Unmarshaler interface.
 func (v *Version) UnmarshalJSON(b []byte) error {
 	var s string
 	if err := json.Unmarshal(b, &s); err != nil {
-		return err
+		var n json.Number
+		if !lenientJSON || json.Unmarshal(b, &n) != nil {
+			return err
+		}
+		s = n.String()
 	}
-	temp, err := NewVersion(s)
+	temp, err := NewVersionEpoch(s)
 	if err != nil {
 		return err
 	}
+	v.epoch = temp.epoch
 	v.major = temp.major
 	v.minor = temp.minor
 	v.patch = temp.patch
@@ -317,6 +913,20 @@ func (v *Version) MarshalJSON() ([]byte, error) {
 	return json.Marshal(v.String())
 }
 
+// parseSegment parses a single numeric segment of a version.
+func parseSegment(s string) (int64, error) {
+	if s == "" {
+		return 0, errors.New("empty segment")
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("%q is not a number", s)
+		}
+	}
+
+	return strconv.ParseInt(s, 10, 64)
+}
+
 func compareSegment(v, o int64) int {
 	if v < o {
 		return -1
@@ -371,6 +981,43 @@ func comparePrerelease(v, o string) int {
 	return 0
 }
 
+// prereleaseRank holds the ranks set with SetPrereleaseRank.
+var prereleaseRank map[string]int
+
+// SetPrereleaseRank sets the order of pre-release labels, such as dev or rc,
+// used when comparing versions. A label with a lower rank is lower, so with
+// the ranks dev: 0, alpha: 1 and rc: 2, 1.0.0-dev is lower than 1.0.0-alpha.
+// The ranks apply to whole identifiers: 1.0.0-rc.1 uses the rank of rc while
+// 1.0.0-rc1 does not. Identifiers that are not both ranked are compared as
+// the spec says. A nil map restores the spec ordering. The ranks are global
+// to the package and should be set before versions are compared.
+func SetPrereleaseRank(ranks map[string]int) {
+	if ranks == nil {
+		prereleaseRank = nil
+		return
+	}
+
+	prereleaseRank = make(map[string]int, len(ranks))
+	for k, r := range ranks {
+		prereleaseRank[k] = r
+	}
+}
+
+// compareRank compares two pre-release identifiers by the ranks set with
+// SetPrereleaseRank. It reports false when they are not both ranked.
+func compareRank(s, o string) (int, bool) {
+	rs, ok := prereleaseRank[s]
+	if !ok {
+		return 0, false
+	}
+	ro, ok := prereleaseRank[o]
+	if !ok {
+		return 0, false
+	}
+
+	return compareSegment(int64(rs), int64(ro)), true
+}
+
 func comparePrePart(s, o string) int {
 	// Fastpath if they are equal
 	if s == o {
@@ -396,11 +1043,15 @@ func comparePrePart(s, o string) int {
 	// When comparing strings "99" is greater than "103". To handle
 	// cases like this we need to detect numbers and compare them.
 
-	oi, n1 := strconv.ParseInt(o, 10, 64)
-	si, n2 := strconv.ParseInt(s, 10, 64)
+	// Only identifiers made of digits are numbers, so -1 is not.
+	oi, n1 := parseSegment(o)
+	si, n2 := parseSegment(s)
 
 	// The case where both are strings compare the strings
 	if n1 != nil && n2 != nil {
+		if d, ok := compareRank(s, o); ok {
+			return d
+		}
 		if s > o {
 			return 1
 		}
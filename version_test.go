@@ -3,6 +3,13 @@ package semver
 import (
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -24,8 +31,13 @@ func TestNewVersion(t *testing.T) {
 		{"v1.2-5", false},
 		{"1.2-beta.5", false},
 		{"v1.2-beta.5", false},
-		{"\n1.2", true},
-		{"\nv1.2", true},
+		{"\n1.2", false},
+		{"\nv1.2", false},
+		{" V1.2.3 ", false},
+		{"V1.2.3", false},
+		{"v 1.2.3", true},
+		{"1.2. 3", true},
+		{"vv1.2.3", true},
 		{"1.2.0-x.Y.0+metadata", false},
 		{"v1.2.0-x.Y.0+metadata", false},
 		{"1.2.0-x.Y.0+metadata-width-hypen", false},
@@ -49,6 +61,465 @@ func TestNewVersion(t *testing.T) {
 	}
 }
 
+func TestNewVersionNormalized(t *testing.T) {
+	for _, s := range []string{" V1.2.3 ", "v1.2.3", "1.2.3", "\t1.2.3\n"} {
+		v, err := NewVersion(s)
+		if err != nil {
+			t.Errorf("Error parsing version %q: %s", s, err)
+			continue
+		}
+
+		if v.String() != "1.2.3" {
+			t.Errorf("Expected %q to parse to 1.2.3 but got %s", s, v)
+		}
+		if v.Original() != s {
+			t.Errorf("Expected the original of %q to be kept but got %q", s, v.Original())
+		}
+	}
+
+	v := MustParse(" V1.2.3 ").IncPatch()
+	if v.Original() != "V1.2.4" {
+		t.Errorf("Expected the V prefix to be kept but got %q", v.Original())
+	}
+}
+
+var versionRegex = regexp.MustCompile("^" + SemVerRegex + "$")
+
+// newVersionRegex parses a version with SemVerRegex, the way NewVersion used
+// to, as a reference for TestNewVersionMatchesRegex.
+func newVersionRegex(v string) (*Version, error) {
+	m := versionRegex.FindStringSubmatch(strings.TrimSpace(v))
+	if m == nil {
+		return nil, ErrInvalidSemVer
+	}
+
+	sv := &Version{metadata: m[8], pre: m[5], original: v}
+
+	var err error
+	sv.major, err = strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return nil, &ParseError{v, "major", err}
+	}
+	if m[2] != "" {
+		sv.minor, err = strconv.ParseInt(strings.TrimPrefix(m[2], "."), 10, 64)
+		if err != nil {
+			return nil, &ParseError{v, "minor", err}
+		}
+	}
+	if m[3] != "" {
+		sv.patch, err = strconv.ParseInt(strings.TrimPrefix(m[3], "."), 10, 64)
+		if err != nil {
+			return nil, &ParseError{v, "patch", err}
+		}
+	}
+
+	return sv, nil
+}
+
+func TestNewVersionMatchesRegex(t *testing.T) {
+	var corpus []string
+	for _, prefix := range []string{"", "v", "V", " ", "vv", "=", "v."} {
+		for _, core := range []string{"", "1", "1.2", "1.2.3", "01.002.0003", "1.2.3.4", "1..2", "1.", "1.2.", ".1",
+			"a.b.c", "1.x", "9223372036854775807.0.0", "1.9223372036854775808", "0.0.99999999999999999999"} {
+			for _, pre := range []string{"", "-", "-beta", "-beta.1", "-0", "-x-y-z.--", "-beta..1", "-beta.", "-.beta", "-β", "-beta_1", "-rc.1-2"} {
+				for _, meta := range []string{"", "+", "+build", "+build.1", "+001", "+build.", "+build+2", "+a-b.c", " "} {
+					corpus = append(corpus, prefix+core+pre+meta)
+				}
+			}
+		}
+	}
+
+	// Random strings over the characters that matter to the grammar.
+	r := rand.New(rand.NewSource(42))
+	chars := "0123456789.-+vVaZx_ "
+	for i := 0; i < 20000; i++ {
+		b := make([]byte, r.Intn(16))
+		for j := range b {
+			b[j] = chars[r.Intn(len(chars))]
+		}
+		corpus = append(corpus, string(b))
+	}
+
+	for _, s := range corpus {
+		v, err := NewVersion(s)
+		e, eerr := newVersionRegex(s)
+		// A second + in the metadata is pointed at by NewVersion where the
+		// regex only fails to match.
+		if pe, ok := err.(*ParseError); ok && pe.Segment == "metadata" && eerr == ErrInvalidSemVer &&
+			strings.Count(s, "+") > 1 {
+			continue
+		}
+		if fmt.Sprint(err) != fmt.Sprint(eerr) {
+			t.Errorf("Expected error %v for %q but got %v", eerr, s, err)
+			continue
+		}
+		if e != nil && *v != *e {
+			t.Errorf("Expected %q to parse to %#v but got %#v", s, *e, *v)
+		}
+	}
+}
+
+func TestNewVersionEpoch(t *testing.T) {
+	tests := []struct {
+		version  string
+		epoch    uint64
+		expected string
+		err      bool
+	}{
+		{"1.2.3", 0, "1.2.3", false},
+		{"2:1.2.3", 2, "2:1.2.3", false},
+		{"0:1.2.3", 0, "1.2.3", false},
+		{"1:v1.2-beta+meta", 1, "1:1.2.0-beta+meta", false},
+		{":1.2.3", 0, "", true},
+		{"a:1.2.3", 0, "", true},
+		{"1:2:1.2.3", 0, "", true},
+		{"1:foo", 0, "", true},
+	}
+
+	for _, tc := range tests {
+		v, err := NewVersionEpoch(tc.version)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected error for version %s", tc.version)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Error parsing version %s: %s", tc.version, err)
+			continue
+		}
+
+		if v.Epoch() != tc.epoch {
+			t.Errorf("Expected epoch %d for %s but got %d", tc.epoch, tc.version, v.Epoch())
+		}
+		if v.String() != tc.expected {
+			t.Errorf("Expected %s for %s but got %s", tc.expected, tc.version, v)
+		}
+		if v.Original() != tc.version {
+			t.Errorf("Expected original %s but got %s", tc.version, v.Original())
+		}
+	}
+
+	if _, err := NewVersion("2:1.2.3"); err == nil {
+		t.Error("Expected NewVersion to reject a version with an epoch")
+	}
+}
+
+func TestNewVersionSep(t *testing.T) {
+	tests := []struct {
+		version  string
+		sep      rune
+		expected string
+		err      bool
+	}{
+		{"1_2_3", '_', "1.2.3", false},
+		{"v1_2", '_', "1.2.0", false},
+		{"1_2_3-rc.1+build.5", '_', "1.2.3-rc.1+build.5", false},
+		{"1_2_3-rc_1", '_', "", true},
+		{"1/2/3", '/', "1.2.3", false},
+		{"1.2.3", '.', "1.2.3", false},
+		{"1.2.3", '_', "", true},
+		{"1_2.3", '_', "", true},
+		{"1__2", '_', "", true},
+		{"1_2_3_4", '_', "", true},
+		{"1-2-3", '-', "", true},
+	}
+
+	for _, tc := range tests {
+		v, err := NewVersionSep(tc.version, tc.sep)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected error for version %s", tc.version)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Error parsing version %s: %s", tc.version, err)
+			continue
+		}
+
+		if v.String() != tc.expected {
+			t.Errorf("Expected %s for %s but got %s", tc.expected, tc.version, v)
+		}
+		if v.Original() != tc.version {
+			t.Errorf("Expected original %s but got %s", tc.version, v.Original())
+		}
+	}
+
+	if _, err := NewVersion("1_2_3"); err == nil {
+		t.Error("Expected NewVersion to reject underscores")
+	}
+}
+
+func TestNewVersionLenient(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+		err      bool
+	}{
+		{"1.2.3", "1.2.3", false},
+		{"1.2.3.", "1.2.3", false},
+		{"1.2.", "1.2.0", false},
+		{"1.2.3.0", "1.2.3", false},
+		{"v1.2.3.0.", "1.2.3", false},
+		{"1.2.3.0-beta.1+build", "1.2.3-beta.1+build", false},
+		{"1.2.3.-rc.1", "1.2.3-rc.1", false},
+		{"1.2.3.4", "", true},
+		{"1.2.3.0.0", "", true},
+		{"1.2.3..", "", true},
+		{"1.2.3.x", "", true},
+		{"1.2.3-beta.", "", true},
+	}
+
+	for _, tc := range tests {
+		v, err := NewVersionLenient(tc.version)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected error for version %s but got %s", tc.version, v)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Error parsing version %s: %s", tc.version, err)
+			continue
+		}
+
+		if v.String() != tc.expected {
+			t.Errorf("Expected %s for %s but got %s", tc.expected, tc.version, v)
+		}
+		if v.Original() != tc.version {
+			t.Errorf("Expected original %s but got %s", tc.version, v.Original())
+		}
+	}
+
+	for _, s := range []string{"1.2.3.", "1.2.3.0"} {
+		if _, err := NewVersion(s); err == nil {
+			t.Errorf("Expected NewVersion to reject %s", s)
+		}
+	}
+
+	_, err := NewVersionLenient("1.2.3.4")
+	if e := `Error parsing version segment of "1.2.3.4": too many segments, the fourth one "4" is not zero`; err == nil || err.Error() != e {
+		t.Errorf("Expected error %q but got %v", e, err)
+	}
+}
+
+func TestCompareEpoch(t *testing.T) {
+	tests := []struct {
+		v1       string
+		v2       string
+		expected int
+	}{
+		{"1:0.0.1", "0:9.9.9", 1},
+		{"0:9.9.9", "1:0.0.1", -1},
+		{"1:0.0.1", "9.9.9", 1},
+		{"0:1.2.3", "1.2.3", 0},
+		{"2:1.2.3", "2:1.2.4", -1},
+		{"2:1.2.3-beta", "1:1.2.3", 1},
+	}
+
+	for _, tc := range tests {
+		v1, err := NewVersionEpoch(tc.v1)
+		if err != nil {
+			t.Errorf("Error parsing version: %s", err)
+		}
+
+		v2, err := NewVersionEpoch(tc.v2)
+		if err != nil {
+			t.Errorf("Error parsing version: %s", err)
+		}
+
+		a := v1.Compare(v2)
+		e := tc.expected
+		if a != e {
+			t.Errorf(
+				"Comparison of '%s' and '%s' failed. Expected '%d', got '%d'",
+				tc.v1, tc.v2, e, a,
+			)
+		}
+	}
+}
+
+func TestNewVersionOverflow(t *testing.T) {
+	tests := []struct {
+		version string
+		segment string
+	}{
+		{"99999999999999999999999.0.0", "major"},
+		{"1.99999999999999999999999.0", "minor"},
+		{"1.0.99999999999999999999999", "patch"},
+		{"9223372036854775808.0.0", "major"},
+	}
+
+	for _, tc := range tests {
+		v, err := NewVersion(tc.version)
+		if err == nil {
+			t.Errorf("Expected error for version %s but got %s", tc.version, v)
+			continue
+		}
+
+		pe, ok := err.(*ParseError)
+		if !ok {
+			t.Errorf("Expected a ParseError for version %s but got %T", tc.version, err)
+			continue
+		}
+		if pe.Segment != tc.segment {
+			t.Errorf("Expected %s to fail on %s but got %s", tc.version, tc.segment, pe.Segment)
+		}
+		if ne, ok := pe.Err.(*strconv.NumError); !ok || ne.Err != strconv.ErrRange {
+			t.Errorf("Expected a range error for version %s but got %s", tc.version, pe.Err)
+		}
+	}
+
+	v, err := NewVersion("9223372036854775807.0.0")
+	if err != nil {
+		t.Fatalf("Error parsing version: %s", err)
+	}
+	if v.Major() != 9223372036854775807 {
+		t.Errorf("Expected the largest major to be kept but got %d", v.Major())
+	}
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		major, minor, patch uint64
+		pre, metadata       string
+		expected            string
+		err                 error
+	}{
+		{1, 2, 3, "", "", "1.2.3", nil},
+		{0, 0, 0, "", "", "0.0.0", nil},
+		{1, 2, 3, "beta.1", "", "1.2.3-beta.1", nil},
+		{1, 2, 3, "", "build.5", "1.2.3+build.5", nil},
+		{1, 2, 3, "rc-1.2", "sha.1a2b", "1.2.3-rc-1.2+sha.1a2b", nil},
+		{math.MaxInt64, 0, 0, "", "", "9223372036854775807.0.0", nil},
+		{1, 2, 3, "beta_1", "", "", ErrInvalidPrerelease},
+		{1, 2, 3, "beta..1", "", "", ErrInvalidPrerelease},
+		{1, 2, 3, "-beta", "", "1.2.3--beta", nil},
+		{1, 2, 3, "beta.", "", "", ErrInvalidPrerelease},
+		{1, 2, 3, "", "+build", "", ErrInvalidMetadata},
+		{1, 2, 3, "", "build 5", "", ErrInvalidMetadata},
+	}
+
+	for _, tc := range tests {
+		v, err := New(tc.major, tc.minor, tc.patch, tc.pre, tc.metadata)
+		if tc.err != nil {
+			if err != tc.err {
+				t.Errorf("Expected error %v for %d.%d.%d-%q+%q but got %v", tc.err, tc.major, tc.minor, tc.patch, tc.pre, tc.metadata, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Error building %s: %s", tc.expected, err)
+			continue
+		}
+
+		if v.String() != tc.expected || v.Original() != tc.expected {
+			t.Errorf("Expected %s but got %s (original %s)", tc.expected, v, v.Original())
+		}
+		if !v.Equal(MustParse(tc.expected)) {
+			t.Errorf("Expected %s to equal the parsed version", v)
+		}
+	}
+
+	if _, err := New(math.MaxInt64+1, 0, 0, "", ""); err == nil {
+		t.Error("Expected an error for a major version above the largest int64")
+	}
+}
+
+func TestLowestVersionSentinel(t *testing.T) {
+	chain := []string{"0.0.0-0", "0.0.0-1", "0.0.0-9", "0.0.0-10", "0.0.0-A", "0.0.0-alpha", "0.0.0-alpha.0", "0.0.0"}
+
+	for i, a := range chain {
+		for j, b := range chain {
+			va, vb := MustParse(a), MustParse(b)
+			e := 0
+			if i < j {
+				e = -1
+			} else if i > j {
+				e = 1
+			}
+			if c := va.Compare(vb); c != e {
+				t.Errorf("Expected %s compared to %s to be %d but got %d", a, b, e, c)
+			}
+		}
+	}
+
+	if v := MustParse("0.0.0-0"); v.Prerelease() != "0" {
+		t.Errorf("Expected the pre-release of 0.0.0-0 to be 0 but got %q", v.Prerelease())
+	}
+}
+
+func TestNewVersionSecondPlus(t *testing.T) {
+	tests := []struct {
+		version string
+		err     string
+	}{
+		{"1.2.3+a+b", `Error parsing metadata segment of "1.2.3+a+b": illegal second + at offset 7`},
+		{"v1.2.3-rc.1+build+extra", `Error parsing metadata segment of "v1.2.3-rc.1+build+extra": illegal second + at offset 17`},
+		{"1.2.3++", `Error parsing metadata segment of "1.2.3++": illegal second + at offset 6`},
+		{"1.2.3-rc+build", ""},
+		{"1.2.3-rc+build.1-x", ""},
+	}
+
+	for _, tc := range tests {
+		_, err := NewVersion(tc.version)
+		if tc.err == "" {
+			if err != nil {
+				t.Errorf("Error parsing version %s: %s", tc.version, err)
+			}
+			continue
+		}
+
+		if _, ok := err.(*ParseError); !ok || err.Error() != tc.err {
+			t.Errorf("Expected error %q for %s but got %v", tc.err, tc.version, err)
+		}
+	}
+}
+
+func TestTryParse(t *testing.T) {
+	tests := []struct {
+		version string
+		segment string
+		partial string
+	}{
+		{"1.2.3", "", "1.2.3"},
+		{"v1.2-beta.5+build", "", "1.2.0-beta.5+build"},
+		{"1.2.beta", "patch", "1.2.0"},
+		{"1.b.3", "minor", "1.0.0"},
+		{"a.2.3", "major", "0.0.0"},
+		{"1.2.3.4", "version", "1.2.3"},
+		{"1..3", "minor", "1.0.0"},
+		{"1.2.99999999999999999999", "patch", "1.2.0"},
+		{"1.2.3-be$ta", "prerelease", "1.2.3"},
+		{"1.2.3-beta+me$ta", "metadata", "1.2.3-beta"},
+	}
+
+	for _, tc := range tests {
+		v, err := TryParse(tc.version)
+		if tc.segment == "" {
+			if err != nil {
+				t.Errorf("Unexpected error for version %s: %s", tc.version, err)
+			}
+		} else if err == nil {
+			t.Errorf("Expected error for version %s", tc.version)
+		} else if err.Segment != tc.segment {
+			t.Errorf("Expected %s to fail on %s but got %s", tc.version, tc.segment, err.Segment)
+		}
+
+		if v == nil {
+			t.Errorf("Expected a version for %s", tc.version)
+			continue
+		}
+		if v.String() != tc.partial {
+			t.Errorf("Expected %s to produce %s but got %s", tc.version, tc.partial, v)
+		}
+		if v.Original() != tc.version {
+			t.Errorf("Expected original %s but got %s", tc.version, v.Original())
+		}
+	}
+}
+
 func TestOriginal(t *testing.T) {
 	tests := []string{
 		"1.2.3",
@@ -103,6 +574,42 @@ func TestParts(t *testing.T) {
 	if v.Metadata() != "build.123" {
 		t.Error("Metadata() returning wrong value")
 	}
+	if v.Segments() != [3]int64{1, 2, 3} {
+		t.Error("Segments() returning wrong value")
+	}
+}
+
+func TestIdentifiers(t *testing.T) {
+	tests := []struct {
+		version  string
+		pre      []string
+		metadata []string
+	}{
+		{"1.2.3", []string{}, []string{}},
+		{"1.2.3-alpha.1", []string{"alpha", "1"}, []string{}},
+		{"1.2.3-rc.10.x-y", []string{"rc", "10", "x-y"}, []string{}},
+		{"1.2.3-0.3.7+build.5", []string{"0", "3", "7"}, []string{"build", "5"}},
+		{"1.2.3+sha.1a2b3c", []string{}, []string{"sha", "1a2b3c"}},
+	}
+
+	for _, tc := range tests {
+		v := MustParse(tc.version)
+
+		pre := v.PrereleaseIdentifiers()
+		if pre == nil || !reflect.DeepEqual(pre, tc.pre) {
+			t.Errorf("Expected pre-release identifiers %q for %s but got %q", tc.pre, tc.version, pre)
+		}
+		metadata := v.MetadataIdentifiers()
+		if metadata == nil || !reflect.DeepEqual(metadata, tc.metadata) {
+			t.Errorf("Expected metadata identifiers %q for %s but got %q", tc.metadata, tc.version, metadata)
+		}
+	}
+
+	v := MustParse("1.2.3-alpha.1")
+	v.PrereleaseIdentifiers()[0] = "beta"
+	if v.Prerelease() != "alpha.1" {
+		t.Errorf("Expected the pre-release to stay alpha.1 but got %s", v.Prerelease())
+	}
 }
 
 func TestString(t *testing.T) {
@@ -141,6 +648,76 @@ func TestString(t *testing.T) {
 	}
 }
 
+func TestIsZero(t *testing.T) {
+	tests := []struct {
+		version string
+		zero    bool
+	}{
+		{"0.0.0", true},
+		{"v0", true},
+		{"0.0.1", false},
+		{"0.1.0", false},
+		{"1.0.0", false},
+		{"0.0.0-alpha", false},
+		{"0.0.0+build", false},
+	}
+
+	for _, tc := range tests {
+		v, err := NewVersion(tc.version)
+		if err != nil {
+			t.Errorf("Error parsing version %s", tc.version)
+			continue
+		}
+
+		if a := v.IsZero(); a != tc.zero {
+			t.Errorf("Expected IsZero of %s to be %t but got %t", tc.version, tc.zero, a)
+		}
+	}
+
+	if !(&Version{}).IsZero() {
+		t.Error("Expected the zero value to be zero")
+	}
+
+	var v *Version
+	if !v.IsZero() {
+		t.Error("Expected a nil version to be zero")
+	}
+
+	if e, _ := NewVersionEpoch("1:0.0.0"); e.IsZero() {
+		t.Error("Expected a version with an epoch not to be zero")
+	}
+}
+
+func TestMajorMinor(t *testing.T) {
+	tests := []struct {
+		version string
+		mm      string
+		mmp     string
+	}{
+		{"1.2.3", "1.2", "1.2.3"},
+		{"v1", "1.0", "1.0.0"},
+		{"1.2.3-beta.1+build", "1.2", "1.2.3"},
+		{"9223372036854775807.9223372036854775807.9223372036854775807",
+			"9223372036854775807.9223372036854775807",
+			"9223372036854775807.9223372036854775807.9223372036854775807"},
+	}
+
+	for _, tc := range tests {
+		v, err := NewVersion(tc.version)
+		if err != nil {
+			t.Errorf("Error parsing version %s", tc.version)
+			continue
+		}
+
+		if a := v.MajorMinor(); a != tc.mm {
+			t.Errorf("Expected MajorMinor %q but got %q", tc.mm, a)
+		}
+		if a := v.MajorMinorPatch(); a != tc.mmp {
+			t.Errorf("Expected MajorMinorPatch %q but got %q", tc.mmp, a)
+		}
+	}
+}
+
 func TestCompare(t *testing.T) {
 	tests := []struct {
 		v1       string
@@ -187,6 +764,250 @@ func TestCompare(t *testing.T) {
 	}
 }
 
+func TestCompareBuild(t *testing.T) {
+	tests := []struct {
+		v1       string
+		v2       string
+		expected int
+	}{
+		{"1.0.0+build.47", "1.0.0+build.48", -1},
+		{"1.0.0+build.48", "1.0.0+build.47", 1},
+		{"1.0.0+build.9", "1.0.0+build.10", -1},
+		{"1.0.0+build.47", "1.0.0+build.47", 0},
+		{"1.0.0", "1.0.0+build.1", -1},
+		{"1.0.0+build.1", "1.0.0", 1},
+		{"1.0.0-beta+build.2", "1.0.0-beta+build.1", 1},
+		{"1.0.0+build.99", "1.0.1+build.1", -1},
+		{"1.0.0-beta+build.99", "1.0.0+build.1", -1},
+	}
+
+	for _, tc := range tests {
+		v1 := MustParse(tc.v1)
+		v2 := MustParse(tc.v2)
+
+		a := v1.CompareBuild(v2)
+		if a != tc.expected {
+			t.Errorf(
+				"Comparison of '%s' and '%s' failed. Expected '%d', got '%d'",
+				tc.v1, tc.v2, tc.expected, a,
+			)
+		}
+	}
+
+	// The metadata is still ignored by Compare.
+	if a := MustParse("1.0.0+build.47").Compare(MustParse("1.0.0+build.48")); a != 0 {
+		t.Errorf("Expected Compare to ignore the metadata but got '%d'", a)
+	}
+}
+
+func TestSortKey(t *testing.T) {
+	raw := []string{
+		"1.2.3",
+		"1.0",
+		"1.3",
+		"2",
+		"0.4.2",
+		"10.0.0",
+		"1.2.3-alpha",
+		"1.2.3-alpha.1",
+		"1.2.3-alpha.beta",
+		"1.2.3-alpha-beta",
+		"1.2.3-beta",
+		"1.2.3-beta.2",
+		"1.2.3-beta.11",
+		"1.2.3-rc.1",
+		"1.2.3-1",
+		"1.2.3-10",
+		"1.2.3-a",
+		"1.2.3-A",
+		"1.2.3-ab",
+		"1.2.3+build.1",
+		"1:0.1.0",
+		"1.0.0--1",
+		"1.0.0--2",
+		"1.0.0-1",
+	}
+
+	if !MustParse("1.0.0--1").LessThan(MustParse("1.0.0--2")) {
+		t.Error("Expected 1.0.0--1 to sort before 1.0.0--2 as -1 is not a number")
+	}
+
+	vs := make([]*Version, len(raw))
+	keys := make([]string, len(raw))
+	for i, r := range raw {
+		v, err := NewVersionEpoch(r)
+		if err != nil {
+			t.Fatalf("Error parsing version: %s", err)
+		}
+		vs[i] = v
+		keys[i] = v.SortKey()
+	}
+
+	sort.Sort(Collection(vs))
+	sort.Strings(keys)
+
+	for i, v := range vs {
+		if keys[i] != v.SortKey() {
+			t.Errorf("Expected key %d to be the key of %s but got %q", i, v, keys[i])
+		}
+	}
+
+	if MustParse("1.2.3").SortKey() != MustParse("v1.2.3+build.1").SortKey() {
+		t.Error("Expected versions only differing by metadata to share a key")
+	}
+
+	e := "00000000000000000000.00000000000000000001.00000000000000000002.00000000000000000003-1rc!000000000000000000001"
+	if a := MustParse("1.2.3-rc.1").SortKey(); a != e {
+		t.Errorf("Expected key %q but got %q", e, a)
+	}
+}
+
+func TestShortString(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected string
+	}{
+		{"1.2.0", "1.2"},
+		{"1.0.0", "1"},
+		{"v1.0", "1"},
+		{"0.0.0", "0"},
+		{"1.0.3", "1.0.3"},
+		{"1.2.3", "1.2.3"},
+		{"1.2.0-rc", "1.2.0-rc"},
+		{"1.0.0+build.1", "1.0.0+build.1"},
+		{"2:1.0.0", "2:1"},
+	}
+
+	for _, tc := range tests {
+		v, err := NewVersionEpoch(tc.version)
+		if err != nil {
+			t.Errorf("Error parsing version %s", tc.version)
+			continue
+		}
+
+		if a := v.ShortString(); a != tc.expected {
+			t.Errorf("Expected %q to give %q but got %q", tc.version, tc.expected, a)
+		}
+	}
+}
+
+func TestSetPrereleaseRank(t *testing.T) {
+	SetPrereleaseRank(map[string]int{"dev": 0, "nightly": 1, "alpha": 2, "beta": 3, "rc": 4})
+	defer SetPrereleaseRank(nil)
+
+	raw := []string{
+		"1.0.0",
+		"1.0.0-rc.1",
+		"1.0.0-beta.2",
+		"1.0.0-alpha",
+		"1.0.0-nightly.20200101",
+		"1.0.0-dev",
+		"1.0.0-beta.10",
+		"1.0.0-zeta",
+		"1.0.0-1",
+	}
+	vs := make([]*Version, len(raw))
+	for i, r := range raw {
+		vs[i] = MustParse(r)
+	}
+	sort.Sort(Collection(vs))
+
+	e := []string{
+		"1.0.0-1",
+		"1.0.0-dev",
+		"1.0.0-nightly.20200101",
+		"1.0.0-alpha",
+		"1.0.0-beta.2",
+		"1.0.0-beta.10",
+		"1.0.0-rc.1",
+		"1.0.0-zeta",
+		"1.0.0",
+	}
+	for i, v := range vs {
+		if v.String() != e[i] {
+			t.Errorf("Expected version %d to be %s but got %s", i, e[i], v)
+		}
+	}
+
+	// Labels that are not ranked keep the spec ordering.
+	if MustParse("1.0.0-dev").Compare(MustParse("1.0.0-alpha")) != -1 {
+		t.Error("Expected dev to be lower than alpha with the ranks")
+	}
+	if MustParse("1.0.0-rc").Compare(MustParse("1.0.0-zeta")) != -1 {
+		t.Error("Expected rc to be lower than zeta without a rank for zeta")
+	}
+
+	SetPrereleaseRank(nil)
+	if MustParse("1.0.0-dev").Compare(MustParse("1.0.0-alpha")) != 1 {
+		t.Error("Expected dev to be greater than alpha once the ranks are unset")
+	}
+}
+
+func TestOnlyMetadataDiffers(t *testing.T) {
+	tests := []struct {
+		v1       string
+		v2       string
+		expected bool
+	}{
+		{"1.0.0+a", "1.0.0+b", true},
+		{"1.0.0", "v1.0.0+b", true},
+		{"1.0.0-rc.1+a", "1.0.0-rc.1+b", true},
+		{"1.0.0+a", "1.0.0+a", false},
+		{"1.0.0+a", "1.0.1", false},
+		{"1.0.0+a", "1.0.1+b", false},
+		{"1.0.0-rc.1+a", "1.0.0+b", false},
+	}
+
+	for _, tc := range tests {
+		if a := MustParse(tc.v1).OnlyMetadataDiffers(MustParse(tc.v2)); a != tc.expected {
+			t.Errorf("Expected %q and %q to give %t but got %t", tc.v1, tc.v2, tc.expected, a)
+		}
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		v1       string
+		v2       string
+		expected int
+	}{
+		{"1.2.3", "1.5.1", -1},
+		{"2.2.3", "1.5.1", 1},
+		{"4.2-beta", "4.2", -1},
+		{"1.2+bar", "1.2+baz", 0},
+	}
+
+	for _, tc := range tests {
+		a := Compare(*MustParse(tc.v1), *MustParse(tc.v2))
+		if a != tc.expected {
+			t.Errorf(
+				"Comparison of '%s' and '%s' failed. Expected '%d', got '%d'",
+				tc.v1, tc.v2, tc.expected, a,
+			)
+		}
+	}
+
+	// Versions are comparable values and can be used as map keys.
+	m := map[Version]int{}
+	m[*MustParse("1.2.3")]++
+	m[*MustParse("1.2.3")]++
+	m[*MustParse("v1.2.3")]++
+	if m[*MustParse("1.2.3")] != 2 || len(m) != 2 {
+		t.Errorf("Unexpected map content: %v", m)
+	}
+
+	// Copies do not share state with the original.
+	v := *MustParse("1.2.3-beta")
+	c := v
+	n, err := c.SetPrerelease("rc")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if v.Prerelease() != "beta" || c.Prerelease() != "beta" || n.Prerelease() != "rc" {
+		t.Error("Expected copies of a version to be independent")
+	}
+}
+
 func TestLessThan(t *testing.T) {
 	tests := []struct {
 		v1       string
@@ -217,6 +1038,9 @@ func TestLessThan(t *testing.T) {
 				tc.v1, tc.v2, e, a,
 			)
 		}
+		if v1.Before(v2) != a {
+			t.Errorf("Before of '%s' and '%s' does not agree with LessThan", tc.v1, tc.v2)
+		}
 	}
 }
 
@@ -255,6 +1079,9 @@ func TestGreaterThan(t *testing.T) {
 				tc.v1, tc.v2, e, a,
 			)
 		}
+		if v1.After(v2) != a {
+			t.Errorf("After of '%s' and '%s' does not agree with GreaterThan", tc.v1, tc.v2)
+		}
 	}
 }
 
@@ -292,6 +1119,115 @@ func TestEqual(t *testing.T) {
 	}
 }
 
+func TestCompareFold(t *testing.T) {
+	tests := []struct {
+		v1       string
+		v2       string
+		expected int
+	}{
+		{"1.2.0-RC.1", "1.2.0-rc.1", 0},
+		{"1.2.0-Beta", "1.2.0-bEtA", 0},
+		{"1.2.0-RC.1", "1.2.0-rc.2", -1},
+		{"1.2.0-RC.1", "1.2.0-beta.1", 1},
+		{"1.2.0-RC.1", "1.2.0", -1},
+		{"1.2.0+META", "1.2.0+meta", 0},
+	}
+
+	for _, tc := range tests {
+		v1, v2 := MustParse(tc.v1), MustParse(tc.v2)
+
+		if a := v1.CompareFold(v2); a != tc.expected {
+			t.Errorf("Expected %s folded compared to %s to be %d but got %d", tc.v1, tc.v2, tc.expected, a)
+		}
+		if a := v1.EqualFold(v2); a != (tc.expected == 0) {
+			t.Errorf("Expected %s and %s folded equal to be %t", tc.v1, tc.v2, tc.expected == 0)
+		}
+		if v1.Prerelease() != MustParse(tc.v1).Prerelease() {
+			t.Errorf("Expected the pre-release of %s to be left as is", tc.v1)
+		}
+	}
+
+	if MustParse("1.2.0-RC.1").Equal(MustParse("1.2.0-rc.1")) {
+		t.Error("Expected Equal to compare pre-releases with case")
+	}
+}
+
+func TestEqualStrings(t *testing.T) {
+	tests := []struct {
+		v1       string
+		v2       string
+		expected bool
+		err      bool
+	}{
+		{"v1.2.3", "1.2.3", true, false},
+		{"V1.2", "1.2.0", true, false},
+		{"1", "v1.0.0", true, false},
+		{" 1.2.3 ", "1.2.3+build", true, false},
+		{"v1.2.3", "1.2.4", false, false},
+		{"1.2.3-beta", "v1.2.3", false, false},
+		{"1.2.3", "foo", false, true},
+		{"1.2.3.4", "1.2.3", false, true},
+	}
+
+	for _, tc := range tests {
+		a, err := EqualStrings(tc.v1, tc.v2)
+		if tc.err && err == nil {
+			t.Errorf("expected but did not get error for: %s and %s", tc.v1, tc.v2)
+		} else if !tc.err && err != nil {
+			t.Errorf("unexpected error for %s and %s: %s", tc.v1, tc.v2, err)
+		}
+		if a != tc.expected {
+			t.Errorf("Expected %q and %q equal to be %t", tc.v1, tc.v2, tc.expected)
+		}
+	}
+}
+
+func TestCompatibleWith(t *testing.T) {
+	tests := []struct {
+		v1       string
+		v2       string
+		expected bool
+	}{
+		{"1.2.0", "1.5.0", true},
+		{"1.2.0", "1.2.0", true},
+		{"1.2.0", "2.0.0", false},
+		{"1.2.0", "1.1.0", false},
+		{"1.2.0", "1.5.0-beta", false},
+		{"1.2.0-beta", "1.5.0-beta", true},
+		{"0.2.0", "0.2.5", true},
+		{"0.2.0", "0.2.0", true},
+		{"0.2.1", "0.2.0", false},
+		{"0.2.0", "0.3.0", false},
+		{"0.2.0", "1.0.0", false},
+		{"0.0.3", "0.0.3", true},
+		{"0.0.3", "0.0.4", false},
+		{"1.2.0", "1:1.5.0", false},
+		{"1:1.2.0", "1:1.5.0", true},
+		{"1:1.2.0", "1.5.0", false},
+	}
+
+	for _, tc := range tests {
+		v1, err := NewVersionEpoch(tc.v1)
+		if err != nil {
+			t.Errorf("Error parsing version: %s", err)
+		}
+
+		v2, err := NewVersionEpoch(tc.v2)
+		if err != nil {
+			t.Errorf("Error parsing version: %s", err)
+		}
+
+		a := v1.CompatibleWith(v2)
+		e := tc.expected
+		if a != e {
+			t.Errorf(
+				"Compatibility of '%s' with '%s' failed. Expected '%t', got '%t'",
+				tc.v1, tc.v2, e, a,
+			)
+		}
+	}
+}
+
 func TestInc(t *testing.T) {
 	tests := []struct {
 		v1               string
@@ -439,6 +1375,87 @@ func TestSetMetadata(t *testing.T) {
 	}
 }
 
+func TestStartPrerelease(t *testing.T) {
+	tests := []struct {
+		v1               string
+		label            string
+		expected         string
+		expectedOriginal string
+		expectedErr      error
+	}{
+		{"1.3.0", "rc", "1.3.0-rc.1", "1.3.0-rc.1", nil},
+		{"v1.3.0", "beta", "1.3.0-beta.1", "v1.3.0-beta.1", nil},
+		{"1.3.0+build.5", "rc", "1.3.0-rc.1", "1.3.0-rc.1", nil},
+		{"1.3.0", "pre.x", "1.3.0-pre.x.1", "1.3.0-pre.x.1", nil},
+		{"1.3.0", "", "", "", ErrInvalidPrerelease},
+		{"1.3.0", "r_c", "", "", ErrInvalidPrerelease},
+		{"1.3.0-rc.1", "rc", "", "", ErrPrereleaseSet},
+	}
+
+	for _, tc := range tests {
+		v1 := MustParse(tc.v1)
+
+		v2, err := v1.StartPrerelease(tc.label)
+		if err != tc.expectedErr {
+			t.Errorf("Expected to get err=%s, but got err=%s", tc.expectedErr, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+
+		if a := v2.String(); a != tc.expected {
+			t.Errorf("Expected version string=%q, but got %q", tc.expected, a)
+		}
+		if a := v2.Original(); a != tc.expectedOriginal {
+			t.Errorf("Expected version original=%q, but got %q", tc.expectedOriginal, a)
+		}
+		if a := v1.String(); a == v2.String() {
+			t.Errorf("Expected %q to be left unchanged", tc.v1)
+		}
+	}
+}
+
+func TestFinalize(t *testing.T) {
+	tests := []struct {
+		v1               string
+		expected         string
+		expectedOriginal string
+	}{
+		{"1.3.0-rc.2", "1.3.0", "1.3.0"},
+		{"v1.3.0-rc.2+build.5", "1.3.0", "v1.3.0"},
+		{"1.3.0+build.5", "1.3.0", "1.3.0"},
+		{"1.3.0", "1.3.0", "1.3.0"},
+		{"v1.3", "1.3.0", "v1.3.0"},
+	}
+
+	for _, tc := range tests {
+		v1, err := NewVersion(tc.v1)
+		if err != nil {
+			t.Errorf("Error parsing version: %s", err)
+		}
+
+		v2 := v1.Finalize()
+		if a := v2.String(); a != tc.expected {
+			t.Errorf("Expected version string=%q, but got %q", tc.expected, a)
+		}
+		if a := v2.Original(); a != tc.expectedOriginal {
+			t.Errorf("Expected version original=%q, but got %q", tc.expectedOriginal, a)
+		}
+		if v1.String() == tc.expected && !v1.Equal(v2) {
+			t.Errorf("Expected %q to be finalized to an equal version", tc.v1)
+		}
+		if v2 == v1 {
+			t.Errorf("Expected %q to be finalized to a copy", tc.v1)
+		}
+	}
+
+	v := MustParse("1.3.0-rc.2")
+	if !v.Finalize().Equal(MustParse("1.3.0")) {
+		t.Error("Expected 1.3.0-rc.2 to be finalized to 1.3.0")
+	}
+}
+
 func TestOriginalVPrefix(t *testing.T) {
 	tests := []struct {
 		version string
@@ -458,6 +1475,26 @@ func TestOriginalVPrefix(t *testing.T) {
 	}
 }
 
+func TestIsUnstableMajor(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"0.0.1", true},
+		{"0.9.3-beta.1", true},
+		{"1.0.0", false},
+		{"1.0.0-alpha", false},
+		{"2.1.0", false},
+	}
+
+	for _, tc := range tests {
+		v := MustParse(tc.version)
+		if a := v.IsUnstableMajor(); a != tc.expected {
+			t.Errorf("Expected IsUnstableMajor() of %q to be %t, got %t", tc.version, tc.expected, a)
+		}
+	}
+}
+
 func TestJsonMarshal(t *testing.T) {
 	sVer := "1.1.1"
 	x, err := NewVersion(sVer)
@@ -488,3 +1525,105 @@ func TestJsonUnmarshal(t *testing.T) {
 		t.Errorf("Error unmarshaling unexpected object content: got=%q want=%q", got, want)
 	}
 }
+
+func TestJsonEpoch(t *testing.T) {
+	v, err := NewVersionEpoch("2:1.2.3")
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("err: %s", err)
+	}
+
+	got := &Version{}
+	if err := json.Unmarshal(out, got); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+	if got.Epoch() != 2 || !got.Equal(v) || got.String() != "2:1.2.3" {
+		t.Errorf("Expected 2:1.2.3 back from %s but got %s", out, got)
+	}
+}
+
+func TestJsonUnmarshalLenient(t *testing.T) {
+	tests := []struct {
+		json     string
+		expected string
+		err      bool
+	}{
+		{`3`, "3.0.0", false},
+		{`3.1`, "3.1.0", false},
+		{`3.10`, "3.10.0", false},
+		{`"1.2.3"`, "1.2.3", false},
+		{`-3`, "", true},
+		{`1e3`, "", true},
+		{`true`, "", true},
+	}
+
+	SetLenientJSON(true)
+	defer SetLenientJSON(false)
+	for _, tc := range tests {
+		ver := &Version{}
+		err := json.Unmarshal([]byte(tc.json), ver)
+		if tc.err {
+			if err == nil {
+				t.Errorf("Expected error unmarshaling %s", tc.json)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Error unmarshaling %s: %s", tc.json, err)
+			continue
+		}
+		if ver.String() != tc.expected {
+			t.Errorf("Expected %s to give %s but got %s", tc.json, tc.expected, ver)
+		}
+	}
+
+	// Numbers are rejected in strict mode.
+	SetLenientJSON(false)
+	if err := json.Unmarshal([]byte(`3`), &Version{}); err == nil {
+		t.Error("Expected a number to be rejected in strict mode")
+	}
+}
+
+func TestSetStrict(t *testing.T) {
+	tests := []struct {
+		version string
+		strict  bool
+	}{
+		{"1.2.3", true},
+		{"1.2.3-beta.1+build", true},
+		{"v1.2.3", false},
+		{"V1.2.3", false},
+		{"1.2", false},
+		{"1", false},
+		{"1.2-beta", false},
+	}
+
+	SetStrict(true)
+	defer SetStrict(false)
+	for _, tc := range tests {
+		if _, err := NewVersion(tc.version); (err == nil) != tc.strict {
+			t.Errorf("Expected %s to parse %t in strict mode but got error %v", tc.version, tc.strict, err)
+		}
+	}
+
+	// Constraints and other formats keep their own rules.
+	if _, err := NewConstraint("^v1.2"); err != nil {
+		t.Errorf("Expected constraints to parse in strict mode but got %s", err)
+	}
+	if _, err := NewVersionLenient("v1.2.3.0"); err != nil {
+		t.Errorf("Expected lenient versions to parse in strict mode but got %s", err)
+	}
+	if _, err := NewVersionEpoch("1:1.2"); err == nil {
+		t.Error("Expected NewVersionEpoch to follow strict mode")
+	}
+
+	SetStrict(false)
+	for _, tc := range tests {
+		if _, err := NewVersion(tc.version); err != nil {
+			t.Errorf("Expected %s to parse out of strict mode but got %s", tc.version, err)
+		}
+	}
+}